@@ -0,0 +1,138 @@
+package calendar
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	productId     = "-//Oyster Mentors//Sessions//EN"
+	dateTimeUTC   = "20060102T150405Z"
+	maxLineLength = 75
+
+	MethodPublish = "PUBLISH"
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+type Calendar struct {
+	Name   string
+	Method string
+	Events []*Event
+}
+
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+type Event struct {
+	UID         string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string
+	Organizer   *Attendee
+	Attendees   []*Attendee
+}
+
+// Bytes renders the calendar as RFC 5545 text with CRLF line endings and folded lines.
+func (c *Calendar) Bytes() []byte {
+	var b strings.Builder
+	writeLine(&b, "BEGIN", "VCALENDAR")
+	writeLine(&b, "VERSION", "2.0")
+	writeLine(&b, "PRODID", productId)
+	writeLine(&b, "CALSCALE", "GREGORIAN")
+	method := c.Method
+	if method == "" {
+		method = MethodPublish
+	}
+	writeLine(&b, "METHOD", method)
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME", escapeText(c.Name))
+	}
+	stamp := time.Now().UTC().Format(dateTimeUTC)
+	for _, event := range c.Events {
+		event.write(&b, stamp)
+	}
+	writeLine(&b, "END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+func (e *Event) write(b *strings.Builder, stamp string) {
+	writeLine(b, "BEGIN", "VEVENT")
+	writeLine(b, "UID", e.UID)
+	writeLine(b, "DTSTAMP", stamp)
+	writeLine(b, "SEQUENCE", strconv.Itoa(e.Sequence))
+	writeLine(b, "DTSTART", e.Start.UTC().Format(dateTimeUTC))
+	writeLine(b, "DTEND", e.End.UTC().Format(dateTimeUTC))
+	writeLine(b, "SUMMARY", escapeText(e.Summary))
+	if e.Description != "" {
+		writeLine(b, "DESCRIPTION", escapeText(e.Description))
+	}
+	if e.Location != "" {
+		writeLine(b, "LOCATION", escapeText(e.Location))
+	}
+	if eventURL := getEventURL(e.URL); eventURL != "" {
+		writeLine(b, "URL", eventURL)
+	}
+	if e.Status != "" {
+		writeLine(b, "STATUS", e.Status)
+	}
+	if e.Organizer != nil {
+		writeLine(b, "ORGANIZER;CN="+quoteParam(e.Organizer.Name), "mailto:"+e.Organizer.Email)
+	}
+	for _, attendee := range e.Attendees {
+		writeLine(b, "ATTENDEE;CN="+quoteParam(attendee.Name)+";ROLE=REQ-PARTICIPANT", "mailto:"+attendee.Email)
+	}
+	writeLine(b, "END", "VEVENT")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// getEventURL returns the URL if it is an absolute http(s) link. URLs are written unescaped, the
+// meeting link is free text of the mentor and must not add properties to the calendar.
+func getEventURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") || parsedURL.Host == "" {
+		return ""
+	}
+	return parsedURL.String()
+}
+
+var lineBreakRemover = strings.NewReplacer("\r", "", "\n", "")
+
+func quoteParam(value string) string {
+	return `"` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(value) + `"`
+}
+
+// writeLine folds content lines longer than 75 octets without splitting UTF-8 characters. Line
+// breaks left in the value are dropped, so a value cannot start a new property.
+func writeLine(b *strings.Builder, name, value string) {
+	line := lineBreakRemover.Replace(name + ":" + value)
+	lineLength := 0
+	for _, r := range line {
+		runeLength := len(string(r))
+		if lineLength+runeLength > maxLineLength {
+			b.WriteString("\r\n ")
+			lineLength = 1
+		}
+		b.WriteRune(r)
+		lineLength += runeLength
+	}
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,186 @@
+package calendar
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Oyster session", want: "Oyster session"},
+		{text: "a, b; c", want: `a\, b\; c`},
+		{text: `back\slash`, want: `back\\slash`},
+		{text: "line 1\nline 2\r\nline 3", want: `line 1\nline 2\nline 3`},
+		{text: "line 1\rATTENDEE:mailto:x@example.com", want: `line 1\nATTENDEE:mailto:x@example.com`},
+	}
+	for _, test := range tests {
+		if got := escapeText(test.text); got != test.want {
+			t.Errorf("escapeText(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "short", value: "Oyster", want: "SUMMARY:Oyster\r\n"},
+		{name: "exactly 75 octets", value: strings.Repeat("a", 67), want: "SUMMARY:" + strings.Repeat("a", 67) + "\r\n"},
+		{name: "folded", value: strings.Repeat("a", 68), want: "SUMMARY:" + strings.Repeat("a", 67) + "\r\n a\r\n"},
+		{name: "line breaks dropped", value: "https://a.example\r\nATTENDEE:mailto:x@example.com", want: "SUMMARY:https://a.exampleATTENDEE:mailto:x@example.com\r\n"},
+		{name: "multi-byte not split", value: strings.Repeat("a", 66) + "ñ", want: "SUMMARY:" + strings.Repeat("a", 66) + "\r\n ñ\r\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			writeLine(&b, "SUMMARY", test.value)
+			if got := b.String(); got != test.want {
+				t.Errorf("writeLine() = %q, want %q", got, test.want)
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+				if len(line) > maxLineLength {
+					t.Errorf("line %q is longer than %d octets", line, maxLineLength)
+				}
+			}
+		})
+	}
+}
+
+func TestGetEventURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://meet.example.com/abc", want: "https://meet.example.com/abc"},
+		{url: "http://meet.example.com/abc?x=1", want: "http://meet.example.com/abc?x=1"},
+		{url: "https://meet.example.com/abc\r\nATTENDEE:mailto:x@example.com", want: ""},
+		{url: "https://meet.example.com/abc\nBEGIN:VEVENT", want: ""},
+		{url: "javascript:alert(1)", want: ""},
+		{url: "meet.example.com/abc", want: ""},
+		{url: "Zoom link will be sent by email", want: ""},
+		{url: "", want: ""},
+	}
+	for _, test := range tests {
+		if got := getEventURL(test.url); got != test.want {
+			t.Errorf("getEventURL(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestCalendarBytesWithInjectedMeetingLink(t *testing.T) {
+	meetingLink := "https://meet.example.com/abc\r\nATTENDEE:mailto:attacker@example.com\r\nEND:VEVENT\r\nBEGIN:VEVENT"
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	calendar := &Calendar{Events: []*Event{{
+		UID:         "1@oystermentors.com",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Oyster session",
+		Description: "Meeting link: " + meetingLink,
+		Location:    meetingLink,
+		URL:         meetingLink,
+	}}}
+	rendered := string(calendar.Bytes())
+	events := 0
+	for _, line := range strings.Split(strings.TrimSuffix(rendered, "\r\n"), "\r\n") {
+		if strings.HasPrefix(line, "URL:") || strings.HasPrefix(line, "ATTENDEE") {
+			t.Errorf("injected property %q in calendar:\n%s", line, rendered)
+		}
+		if line == "BEGIN:VEVENT" {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("calendar has %d events, want 1:\n%s", events, rendered)
+	}
+}
+
+func TestCalendarBytes(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	event := &Event{
+		UID:         "1@oystermentors.com",
+		Sequence:    2,
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Oyster session with Ana, Bob",
+		Description: "Meeting link: https://meet.example.com/abc",
+		URL:         "https://meet.example.com/abc",
+		Status:      StatusConfirmed,
+		Organizer:   &Attendee{Name: "Oyster", Email: "info@oystermentors.com"},
+		Attendees:   []*Attendee{{Name: `Ana "A"`, Email: "ana@example.com"}},
+	}
+	tests := []struct {
+		name     string
+		calendar *Calendar
+		want     []string
+	}{
+		{
+			name:     "feed",
+			calendar: &Calendar{Name: "Oyster sessions", Events: []*Event{event}},
+			want: []string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"PRODID:" + productId,
+				"CALSCALE:GREGORIAN",
+				"METHOD:PUBLISH",
+				"X-WR-CALNAME:Oyster sessions",
+				"BEGIN:VEVENT",
+				"UID:1@oystermentors.com",
+				"DTSTAMP:",
+				"SEQUENCE:2",
+				"DTSTART:20240301T090000Z",
+				"DTEND:20240301T100000Z",
+				`SUMMARY:Oyster session with Ana\, Bob`,
+				"DESCRIPTION:Meeting link: https://meet.example.com/abc",
+				"URL:https://meet.example.com/abc",
+				"STATUS:CONFIRMED",
+				`ORGANIZER;CN="Oyster":mailto:info@oystermentors.com`,
+				`ATTENDEE;CN="Ana 'A'";ROLE=REQ-PARTICIPANT:mailto:ana@example.com`,
+				"END:VEVENT",
+				"END:VCALENDAR",
+			},
+		},
+		{
+			name:     "cancel without events",
+			calendar: &Calendar{Method: MethodCancel},
+			want: []string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"PRODID:" + productId,
+				"CALSCALE:GREGORIAN",
+				"METHOD:CANCEL",
+				"END:VCALENDAR",
+			},
+		},
+	}
+	stamp := regexp.MustCompile(`^DTSTAMP:\d{8}T\d{6}Z$`)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered := string(test.calendar.Bytes())
+			if !strings.HasSuffix(rendered, "\r\n") {
+				t.Fatalf("calendar does not end with CRLF: %q", rendered)
+			}
+			lines := strings.Split(strings.TrimSuffix(rendered, "\r\n"), "\r\n")
+			if len(lines) != len(test.want) {
+				t.Fatalf("calendar has %d lines, want %d:\n%s", len(lines), len(test.want), rendered)
+			}
+			for i, line := range lines {
+				if test.want[i] == "DTSTAMP:" {
+					if !stamp.MatchString(line) {
+						t.Errorf("line %d = %q, want a UTC DTSTAMP", i, line)
+					}
+					continue
+				}
+				if line != test.want[i] {
+					t.Errorf("line %d = %q, want %q", i, line, test.want[i])
+				}
+			}
+		})
+	}
+}
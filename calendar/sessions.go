@@ -0,0 +1,90 @@
+package calendar
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"oysterProject/model"
+)
+
+const (
+	uidDomain      = "oystermentors.com"
+	organizerName  = "Oyster"
+	organizerEmail = "info@oystermentors.com"
+)
+
+// NewSessionEvent renders the session as seen by the participant with the given id.
+func NewSessionEvent(session *model.SessionResponse, userId primitive.ObjectID) *Event {
+	counterpart := session.Mentee
+	if session.Mentee != nil && session.Mentee.UserId == userId {
+		counterpart = session.Mentor
+	}
+	summary := "Oyster session"
+	if counterpart != nil && counterpart.Name != "" {
+		summary = "Oyster session with " + counterpart.Name
+	}
+	description := ""
+	if session.MeetingLink != "" {
+		description = "Meeting link: " + session.MeetingLink
+	}
+	event := &Event{
+		UID:         GetSessionUID(session.SessionId),
+		Sequence:    session.Sequence,
+		Summary:     summary,
+		Description: description,
+		Location:    session.MeetingLink,
+		URL:         session.MeetingLink,
+		Status:      getEventStatus(session.SessionStatus),
+	}
+	if session.SessionTimeStart != nil && session.SessionTimeEnd != nil {
+		event.Start, event.End = *session.SessionTimeStart, *session.SessionTimeEnd
+	}
+	return event
+}
+
+func GetSessionUID(sessionId primitive.ObjectID) string {
+	return sessionId.Hex() + "@" + uidDomain
+}
+
+// IsSessionInFeed reports whether the session was ever agreed on, requests that were
+// never confirmed are left out of the feed.
+func IsSessionInFeed(session *model.SessionResponse) bool {
+	if session.SessionTimeStart == nil || session.SessionTimeEnd == nil {
+		return false
+	}
+	switch session.SessionStatus {
+	case model.CreatedByMentee, model.PendingByMentor, model.Expired:
+		return false
+	default:
+		return true
+	}
+}
+
+func getEventStatus(status model.Status) string {
+	switch status {
+	case model.CanceledByMentor, model.CanceledByMentee:
+		return StatusCancelled
+	case model.ReschedulingByMentor, model.ReschedulingByMentee:
+		return StatusTentative
+	default:
+		return StatusConfirmed
+	}
+}
+
+// NewSessionInvite builds the iTIP message for the session participants. It always carries the
+// booked time, a proposed time is sent only once the reschedule is confirmed.
+func NewSessionInvite(session *model.SessionResponse, method string) *Calendar {
+	event := NewSessionEvent(session, primitive.NilObjectID)
+	event.Summary = "Oyster session"
+	if session.Mentor != nil && session.Mentee != nil {
+		event.Summary = "Oyster session: " + session.Mentor.Name + " & " + session.Mentee.Name
+	}
+	if method == MethodCancel {
+		event.Status = StatusCancelled
+	}
+	event.Organizer = &Attendee{Name: organizerName, Email: organizerEmail}
+	for _, participant := range []*model.UserImage{session.Mentor, session.Mentee} {
+		if participant != nil && participant.Email != "" {
+			event.Attendees = append(event.Attendees, &Attendee{Name: participant.Name, Email: participant.Email})
+		}
+	}
+	return &Calendar{Method: method, Events: []*Event{event}}
+}
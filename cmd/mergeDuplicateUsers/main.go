@@ -0,0 +1,55 @@
+// Command mergeDuplicateUsers merges accounts registered more than once with the same email in
+// a different case and normalises the stored emails. Run it once before deploying the unique
+// email index, the server does not create the index while duplicates exist. Use -dry-run to
+// review the duplicates first.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"oysterProject/database"
+	"time"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report duplicates, change nothing")
+	flag.Parse()
+
+	if err := database.ConnectToMongoDB(); err != nil {
+		log.Fatal(err)
+	}
+	defer database.CloseMongoDBConnection()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	duplicates, err := database.FindDuplicateUsers(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, duplicate := range duplicates {
+		log.Printf("%s: keeping user(%s), merging %v with %d sessions and %d reviews\n",
+			duplicate.Email, duplicate.PrimaryId.Hex(), duplicate.DuplicateIds, duplicate.Sessions, duplicate.Reviews)
+		if *dryRun {
+			continue
+		}
+		if err = database.MergeDuplicateUsers(ctx, duplicate); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Printf("%d emails with duplicate accounts\n", len(duplicates))
+
+	if *dryRun {
+		count, err := database.CountUnnormalizedUserEmails(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%d emails would be normalised\n", count)
+		return
+	}
+	count, err := database.NormalizeUserEmails(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d emails normalised\n", count)
+}
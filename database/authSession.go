@@ -2,18 +2,30 @@ package database
 
 import (
 	"context"
+	"errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"oysterProject/model"
+	"oysterProject/utils"
 	"time"
 )
 
+const authSessionPublicIdSize = 16
+
 func SaveAuthSession(s *model.AuthSession) (string, error) {
 	collection := GetCollection(AuthSessionCollectionName)
 	ctx, cancel := withTimeout(context.Background())
 	defer cancel()
+	if s.PublicId == "" {
+		publicId, err := utils.GenerateRandomToken(authSessionPublicIdSize)
+		if err != nil {
+			return "", err
+		}
+		s.PublicId = publicId
+	}
 	result, err := collection.InsertOne(ctx, s)
 	if err != nil {
 		log.Printf("Error saving auth session in db: %v\n", err)
@@ -22,12 +34,18 @@ func SaveAuthSession(s *model.AuthSession) (string, error) {
 	return result.InsertedID.(primitive.ObjectID).Hex(), err
 }
 
-func UpdateAuthSession(sessionId primitive.ObjectID, expiryTime time.Time) (*model.AuthSession, error) {
+// UpdateAuthSession slides the expiry and records the device the session was last used from.
+func UpdateAuthSession(sessionId primitive.ObjectID, expiryTime time.Time, ip, userAgent string) (*model.AuthSession, error) {
 	collection := GetCollection(AuthSessionCollectionName)
 	ctx, cancel := withTimeout(context.Background())
 	defer cancel()
 	filter := bson.M{"_id": sessionId}
-	updateOp := bson.M{"$set": bson.M{"expiry": expiryTime.Unix()}}
+	updateOp := bson.M{"$set": bson.M{
+		"expiry":     expiryTime.Unix(),
+		"lastSeenAt": time.Now().UTC(),
+		"ip":         ip,
+		"userAgent":  userAgent,
+	}}
 	var result model.AuthSession
 	err := collection.FindOneAndUpdate(ctx, filter, updateOp, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&result)
 	if err != nil {
@@ -67,3 +85,97 @@ func DeleteAuthSession(s *model.AuthSession) error {
 	}
 	return nil
 }
+
+// DeleteUserAuthSessions signs the user out of every device.
+func DeleteUserAuthSessions(userId primitive.ObjectID) error {
+	collection := GetCollection(AuthSessionCollectionName)
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	result, err := collection.DeleteMany(ctx, bson.M{"userId": userId})
+	if err != nil {
+		log.Printf("Error deleting auth sessions of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	log.Printf("Deleted %d auth sessions of user(%s)\n", result.DeletedCount, userId.Hex())
+	return nil
+}
+
+// GetUserAuthSessions returns the sessions of the user which have not expired, most recently
+// used first.
+func GetUserAuthSessions(userId primitive.ObjectID) ([]*model.AuthSession, error) {
+	collection := GetCollection(AuthSessionCollectionName)
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"userId": userId, "expiry": bson.M{"$gte": time.Now().Unix()}}
+	findOptions := options.Find().SetSort(bson.D{{"lastSeenAt", -1}, {"_id", -1}})
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		log.Printf("Error getting auth sessions of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	sessions := make([]*model.AuthSession, 0)
+	if err = cursor.All(ctx, &sessions); err != nil {
+		log.Printf("Error decoding auth sessions of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.PublicId == "" {
+			if err = assignAuthSessionPublicId(ctx, session); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sessions, nil
+}
+
+// assignAuthSessionPublicId gives sessions created before public ids were introduced one.
+func assignAuthSessionPublicId(ctx context.Context, session *model.AuthSession) error {
+	publicId, err := utils.GenerateRandomToken(authSessionPublicIdSize)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": session.SessionId, "publicId": bson.M{"$exists": false}}
+	updateOp := bson.M{"$set": bson.M{"publicId": publicId}}
+	var updated model.AuthSession
+	err = GetCollection(AuthSessionCollectionName).FindOneAndUpdate(ctx, filter, updateOp, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Assigned concurrently, read the stored id.
+		err = GetCollection(AuthSessionCollectionName).FindOne(ctx, bson.M{"_id": session.SessionId}).Decode(&updated)
+	}
+	if err != nil {
+		log.Printf("Error assigning public id to session of user(%s): %v\n", session.UserId.Hex(), err)
+		return err
+	}
+	session.PublicId = updated.PublicId
+	return nil
+}
+
+// DeleteUserAuthSession signs out one device of the user, the session is found by its public id.
+func DeleteUserAuthSession(userId primitive.ObjectID, publicId string) error {
+	collection := GetCollection(AuthSessionCollectionName)
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	result, err := collection.DeleteOne(ctx, bson.M{"publicId": publicId, "userId": userId})
+	if err != nil {
+		log.Printf("Error deleting session of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteOtherAuthSessions signs the user out of every device except the current session.
+func DeleteOtherAuthSessions(userId, currentSessionId primitive.ObjectID) (int64, error) {
+	collection := GetCollection(AuthSessionCollectionName)
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"userId": userId, "_id": bson.M{"$ne": currentSessionId}}
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		log.Printf("Error deleting other auth sessions of user(%s): %v\n", userId.Hex(), err)
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"oysterProject/model"
+)
+
+func AddAvailabilityException(userId primitive.ObjectID, exception *model.AvailabilityException) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	exception.Id = primitive.NewObjectID()
+	filter := bson.M{"_id": userId}
+	updateOp := bson.M{"$push": bson.M{"availabilityExceptions": exception}}
+	result, err := collection.UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to add availability exception for user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func UpdateAvailabilityException(userId primitive.ObjectID, exception *model.AvailabilityException) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	filter := bson.M{"_id": userId, "availabilityExceptions._id": exception.Id}
+	updateOp := bson.M{"$set": bson.M{"availabilityExceptions.$": exception}}
+	result, err := collection.UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to update availability exception(%s) for user(%s): %v\n", exception.Id.Hex(), userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func DeleteAvailabilityException(userId, exceptionId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	filter := bson.M{"_id": userId, "availabilityExceptions._id": exceptionId}
+	updateOp := bson.M{"$pull": bson.M{"availabilityExceptions": bson.M{"_id": exceptionId}}}
+	result, err := collection.UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to delete availability exception(%s) for user(%s): %v\n", exceptionId.Hex(), userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+const (
+	bookingLockTimeout       = 15 * time.Second
+	bookingLockRetries       = 3
+	bookingLockRetryInterval = 100 * time.Millisecond
+)
+
+// withMentorBookingLock runs action while holding the booking lock document of the mentor,
+// so overlap checks and session writes for one mentor never interleave between requests.
+// The lock expires on its own if the holder dies before releasing it.
+func withMentorBookingLock(mentorId primitive.ObjectID, action func(ctx context.Context) error) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(BookingLockCollectionName)
+	owner := primitive.NewObjectID()
+
+	var err error
+	for attempt := 0; attempt < bookingLockRetries; attempt++ {
+		now := time.Now()
+		filter := bson.M{"_id": mentorId, "lockedUntil": bson.M{"$lt": now}}
+		updateOp := bson.M{"$set": bson.M{"owner": owner, "lockedUntil": now.Add(bookingLockTimeout)}}
+		_, err = collection.UpdateOne(ctx, filter, updateOp, options.Update().SetUpsert(true))
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+		time.Sleep(bookingLockRetryInterval)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		log.Printf("Booking lock for mentor(%s) is held by another request\n", mentorId.Hex())
+		return utils.MentorBookingInProgress
+	} else if err != nil {
+		log.Printf("Failed to acquire booking lock for mentor(%s): %v\n", mentorId.Hex(), err)
+		return err
+	}
+	defer func() {
+		if _, err := collection.DeleteOne(context.Background(), bson.M{"_id": mentorId, "owner": owner}); err != nil {
+			log.Printf("Failed to release booking lock for mentor(%s): %v\n", mentorId.Hex(), err)
+		}
+	}()
+
+	return action(ctx)
+}
+
+// checkMentorSlotIsFree compares blockedTimeRange, the session time with its buffers,
+// against the buffered time of every active session of the mentor.
+func checkMentorSlotIsFree(ctx context.Context, mentorId primitive.ObjectID, blockedTimeRange model.TimeSlot, excludeSessionId primitive.ObjectID) error {
+	sessions, err := findMentorBookedSessions(ctx, mentorId, blockedTimeRange.StartTime, blockedTimeRange.EndTime)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.SessionId == excludeSessionId {
+			continue
+		}
+		for _, sessionTimeRange := range session.GetBlockedTimeRanges() {
+			if sessionTimeRange.Overlaps(blockedTimeRange) {
+				log.Printf("Slot %s - %s of mentor(%s) overlaps with session(%s)\n", blockedTimeRange.StartTime, blockedTimeRange.EndTime, mentorId.Hex(), session.SessionId.Hex())
+				return utils.SessionSlotIsTaken
+			}
+		}
+	}
+	return nil
+}
+
+// checkMentorBookingLimits counts the active sessions of the mentor on the day and in the week
+// of sessionTimeStart. Must be called under the mentor booking lock.
+func checkMentorBookingLimits(ctx context.Context, mentor *model.User, sessionTimeStart time.Time, excludeSessionId primitive.ObjectID) error {
+	if !mentor.BookingRules.HasSessionLimits() {
+		return nil
+	}
+	location := utils.GetLocation(mentor.TimeZone)
+	week := model.GetWeekRange(sessionTimeStart, location)
+	sessions, err := findMentorBookedSessions(ctx, mentor.Id, week.StartTime, week.EndTime)
+	if err != nil {
+		return err
+	}
+	var bookedSessions []*model.Session
+	for _, session := range sessions {
+		if session.SessionId != excludeSessionId {
+			bookedSessions = append(bookedSessions, session)
+		}
+	}
+	return mentor.BookingRules.CheckSessionLimits(bookedSessions, sessionTimeStart, location)
+}
+
+func checkMenteePendingSessions(ctx context.Context, mentor *model.User, menteeId primitive.ObjectID) error {
+	if mentor.BookingRules == nil || mentor.BookingRules.MaxPendingPerMentee == 0 {
+		return nil
+	}
+	collection := GetCollection(SessionCollectionName)
+	filter := bson.M{
+		"mentorId":      mentor.Id,
+		"menteeId":      menteeId,
+		"sessionStatus": bson.M{"$lt": model.Confirmed},
+	}
+	pendingSessionsCount, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Printf("Failed to count pending sessions of mentee(%s) for mentor(%s): %v\n", menteeId.Hex(), mentor.Id.Hex(), err)
+		return err
+	}
+	return mentor.BookingRules.CheckPendingSessions(pendingSessionsCount)
+}
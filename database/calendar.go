@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"oysterProject/model"
+)
+
+func GetUserByCalendarToken(token string) (*model.User, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	filter := bson.M{"calendarToken": token}
+	var user model.User
+	err := collection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		handleFindError(err, "calendar token", "user")
+		return nil, err
+	}
+	return &user, nil
+}
+
+func UpdateCalendarToken(userId primitive.ObjectID, token string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	filter := bson.M{"_id": userId}
+	updateOp := bson.M{"$set": bson.M{"calendarToken": token}}
+	result, err := collection.UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to update calendar token for user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetUserCalendarSessions returns sessions where the user is either the mentor or the mentee.
+func GetUserCalendarSessions(userId primitive.ObjectID) ([]*model.SessionResponse, error) {
+	mentorSessions, err := GetUserSessions(userId, true)
+	if err != nil {
+		return nil, err
+	}
+	menteeSessions, err := GetUserSessions(userId, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(mentorSessions, menteeSessions...), nil
+}
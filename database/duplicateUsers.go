@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+)
+
+// DuplicateUsers are accounts whose emails differ only in case or surrounding spaces.
+// PrimaryId is the account that is kept.
+type DuplicateUsers struct {
+	Email        string               `bson:"_id"`
+	PrimaryId    primitive.ObjectID   `bson:"primaryId"`
+	DuplicateIds []primitive.ObjectID `bson:"duplicateIds"`
+	Sessions     int64                `bson:"-"`
+	Reviews      int64                `bson:"-"`
+}
+
+// normalizedEmailExpression matches utils.NormalizeEmail.
+var normalizedEmailExpression = bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$email"}}}
+
+var unnormalizedEmailFilter = bson.M{
+	"email": bson.M{"$type": "string"},
+	"$expr": bson.M{"$ne": bson.A{"$email", normalizedEmailExpression}},
+}
+
+// FindDuplicateUsers groups users by normalised email. The approved account is kept, otherwise
+// the oldest one, so mentor profiles are not lost.
+func FindDuplicateUsers(ctx context.Context) ([]*DuplicateUsers, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"email": bson.M{"$type": "string"}}}},
+		{{"$sort", bson.D{{"isApproved", -1}, {"_id", 1}}}},
+		{{"$group", bson.M{
+			"_id":   normalizedEmailExpression,
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{"$match", bson.M{"count": bson.M{"$gt": 1}}}},
+		{{"$project", bson.M{
+			"primaryId":    bson.M{"$first": "$ids"},
+			"duplicateIds": bson.M{"$slice": bson.A{"$ids", 1, "$count"}},
+		}}},
+	}
+	cursor, err := GetCollection(UserCollectionName).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("FindDuplicateUsers: failed to group users: %v\n", err)
+		return nil, err
+	}
+	var duplicates []*DuplicateUsers
+	if err = cursor.All(ctx, &duplicates); err != nil {
+		log.Printf("FindDuplicateUsers: failed to decode users: %v\n", err)
+		return nil, err
+	}
+	for _, duplicate := range duplicates {
+		participantFilter := bson.M{"$or": bson.A{
+			bson.M{"menteeId": bson.M{"$in": duplicate.DuplicateIds}},
+			bson.M{"mentorId": bson.M{"$in": duplicate.DuplicateIds}},
+		}}
+		if duplicate.Sessions, err = GetCollection(SessionCollectionName).CountDocuments(ctx, participantFilter); err != nil {
+			return nil, err
+		}
+		if duplicate.Reviews, err = GetCollection(ReviewCollectionName).CountDocuments(ctx, participantFilter); err != nil {
+			return nil, err
+		}
+	}
+	return duplicates, nil
+}
+
+// duplicateUserReferences lists the fields which are moved to the primary account. Session
+// reminders reference the session and follow it.
+var duplicateUserReferences = map[string][]string{
+	SessionCollectionName:      {"menteeId", "mentorId"},
+	ReviewCollectionName:       {"menteeId", "mentorId"},
+	NotificationCollectionName: {"userId", "actorId"},
+	EmailOutboxCollectionName:  {"userId"},
+}
+
+// duplicateUserCredentials lists the documents of the duplicates which are deleted. Their
+// sessions and tokens must not sign in to the primary account.
+var duplicateUserCredentials = map[string]string{
+	AuthSessionCollectionName:             "userId",
+	PasswordResetTokenCollectionName:      "userId",
+	TwoFactorChallengeCollectionName:      "userId",
+	NotificationPreferencesCollectionName: "_id",
+}
+
+// MergeDuplicateUsers moves the sessions, reviews, notifications and emails of the duplicates to
+// the primary account, deletes their credentials and preferences and then the duplicates.
+func MergeDuplicateUsers(ctx context.Context, duplicate *DuplicateUsers) error {
+	for collectionName, fields := range duplicateUserReferences {
+		collection := GetCollection(collectionName)
+		for _, field := range fields {
+			filter := bson.M{field: bson.M{"$in": duplicate.DuplicateIds}}
+			updateOp := bson.M{"$set": bson.M{field: duplicate.PrimaryId}}
+			if _, err := collection.UpdateMany(ctx, filter, updateOp); err != nil {
+				log.Printf("MergeDuplicateUsers: failed to move %s.%s to user(%s): %v\n", collectionName, field, duplicate.PrimaryId.Hex(), err)
+				return err
+			}
+		}
+	}
+	for collectionName, field := range duplicateUserCredentials {
+		filter := bson.M{field: bson.M{"$in": duplicate.DuplicateIds}}
+		if _, err := GetCollection(collectionName).DeleteMany(ctx, filter); err != nil {
+			log.Printf("MergeDuplicateUsers: failed to delete %s of duplicates: %v\n", collectionName, err)
+			return err
+		}
+	}
+	if _, err := GetCollection(UserCollectionName).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicate.DuplicateIds}}); err != nil {
+		log.Printf("MergeDuplicateUsers: failed to delete duplicates of user(%s): %v\n", duplicate.PrimaryId.Hex(), err)
+		return err
+	}
+	return nil
+}
+
+// NormalizeUserEmails stores every email in the form returned by utils.NormalizeEmail. It must
+// run after the duplicates are merged, otherwise the unique index rejects the update.
+func NormalizeUserEmails(ctx context.Context) (int64, error) {
+	updateOp := mongo.Pipeline{{{"$set", bson.M{"email": normalizedEmailExpression}}}}
+	result, err := GetCollection(UserCollectionName).UpdateMany(ctx, unnormalizedEmailFilter, updateOp)
+	if err != nil {
+		log.Printf("NormalizeUserEmails: failed to update emails: %v\n", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func CountUnnormalizedUserEmails(ctx context.Context) (int64, error) {
+	return GetCollection(UserCollectionName).CountDocuments(ctx, unnormalizedEmailFilter)
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+// SaveEmailEvents stores the events and the latest event of every outbox email. SendGrid may
+// post an event more than once, events are keyed by their SendGrid id.
+func SaveEmailEvents(events []*model.SendGridEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	var eventModels, outboxModels []mongo.WriteModel
+	for _, event := range events {
+		filter := bson.M{"_id": event.EventId}
+		eventModels = append(eventModels, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$setOnInsert": event}).SetUpsert(true))
+		if event.OutboxEmailId.IsZero() {
+			continue
+		}
+		eventTime := time.Unix(event.Timestamp, 0).UTC()
+		outboxFilter := bson.M{
+			"_id": event.OutboxEmailId,
+			"$or": bson.A{
+				bson.M{"lastEventAt": bson.M{"$exists": false}},
+				bson.M{"lastEventAt": bson.M{"$lte": eventTime}},
+			},
+		}
+		outboxUpdate := bson.M{"$set": bson.M{"lastEvent": event.Event, "lastEventAt": eventTime}}
+		outboxModels = append(outboxModels, mongo.NewUpdateOneModel().SetFilter(outboxFilter).SetUpdate(outboxUpdate))
+	}
+	bulkOptions := options.BulkWrite().SetOrdered(false)
+	if _, err := GetCollection(EmailEventCollectionName).BulkWrite(ctx, eventModels, bulkOptions); err != nil && !mongo.IsDuplicateKeyError(err) {
+		log.Printf("SaveEmailEvents: failed to save events: %v\n", err)
+		return err
+	}
+	if len(outboxModels) == 0 {
+		return nil
+	}
+	if _, err := GetCollection(EmailOutboxCollectionName).BulkWrite(ctx, outboxModels, bulkOptions); err != nil {
+		log.Printf("SaveEmailEvents: failed to update outbox emails: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// MarkEmailUndeliverable stores the deliverability on every user with the address.
+func MarkEmailUndeliverable(email string, deliverability *model.EmailDeliverability) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"email": utils.NormalizeEmail(email)}
+	updateOp := bson.M{"$set": bson.M{"emailDeliverability": deliverability}}
+	result, err := GetCollection(UserCollectionName).UpdateMany(ctx, filter, updateOp, options.Update().SetCollation(emailCollation))
+	if err != nil {
+		log.Printf("Failed to mark email(%s) as undeliverable: %v\n", email, err)
+		return err
+	}
+	log.Printf("Email(%s) marked as %s for %d users\n", email, deliverability.Status, result.ModifiedCount)
+	return nil
+}
+
+func IsEmailUndeliverable(email string) (bool, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"email": utils.NormalizeEmail(email), "emailDeliverability": bson.M{"$exists": true}}
+	count, err := GetCollection(UserCollectionName).CountDocuments(ctx, filter, options.Count().SetLimit(1).SetCollation(emailCollation))
+	if err != nil {
+		log.Printf("Failed to check deliverability of email(%s): %v\n", email, err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ResetEmailDeliverability is called when the user changes the address.
+func ResetEmailDeliverability(userId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	updateOp := bson.M{"$unset": bson.M{"emailDeliverability": ""}}
+	if _, err := GetCollection(UserCollectionName).UpdateOne(ctx, bson.M{"_id": userId}, updateOp); err != nil {
+		log.Printf("Failed to reset email deliverability of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	return nil
+}
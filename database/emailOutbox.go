@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+const emailClaimTimeout = 5 * time.Minute
+
+// EnqueueEmail stores the email for delivery. An email whose DedupeKey is already in the
+// outbox is not added again.
+func EnqueueEmail(email *model.OutboxEmail) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(EmailOutboxCollectionName)
+	now := time.Now().UTC()
+	email.Status = model.EmailQueued
+	email.Attempts = 0
+	email.NextAttemptAt = now
+	email.CreatedAt = now
+	if email.DedupeKey == "" {
+		result, err := collection.InsertOne(ctx, email)
+		if err != nil {
+			log.Printf("EnqueueEmail: failed to insert email to %s: %v\n", email.ToEmail, err)
+			return err
+		}
+		email.Id = result.InsertedID.(primitive.ObjectID)
+		return nil
+	}
+	filter := bson.M{"dedupeKey": email.DedupeKey}
+	updateOp := bson.M{"$setOnInsert": email}
+	_, err := collection.UpdateOne(ctx, filter, updateOp, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// enqueued concurrently by another instance
+		return nil
+	} else if err != nil {
+		log.Printf("EnqueueEmail: failed to upsert email(%s): %v\n", email.DedupeKey, err)
+		return err
+	}
+	return nil
+}
+
+// ClaimDueEmail atomically hands one email due for delivery to the owner. Emails whose claim
+// expired because the owner stopped are claimed again.
+func ClaimDueEmail(ctx context.Context, owner string, now time.Time) (*model.OutboxEmail, error) {
+	collection := GetCollection(EmailOutboxCollectionName)
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{model.EmailQueued, model.EmailFailed}}, "nextAttemptAt": bson.M{"$lte": now}},
+			bson.M{"status": model.EmailSending, "claimedUntil": bson.M{"$lt": now}},
+		},
+	}
+	updateOp := bson.M{
+		"$set": bson.M{
+			"status":       model.EmailSending,
+			"claimedBy":    owner,
+			"claimedUntil": now.Add(emailClaimTimeout),
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{"nextAttemptAt", 1}}).
+		SetReturnDocument(options.After)
+	var email model.OutboxEmail
+	err := collection.FindOneAndUpdate(ctx, filter, updateOp, findOptions).Decode(&email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("ClaimDueEmail: failed to claim email: %v\n", err)
+		return nil, err
+	}
+	return &email, nil
+}
+
+// CompleteEmail stores the delivery result of a claimed email. A failed email is retried with
+// exponential backoff until it runs out of attempts and becomes dead.
+func CompleteEmail(ctx context.Context, email *model.OutboxEmail, sendErr error) (model.EmailStatus, error) {
+	collection := GetCollection(EmailOutboxCollectionName)
+	filter := bson.M{"_id": email.Id, "claimedBy": email.ClaimedBy, "status": model.EmailSending}
+	completion := newEmailCompletion(email, sendErr, time.Now().UTC())
+	result, err := collection.UpdateOne(ctx, filter, completion.updateOp)
+	if err != nil {
+		log.Printf("CompleteEmail: failed to update email(%s): %v\n", email.Id.Hex(), err)
+		return completion.status, err
+	}
+	if result.MatchedCount == 0 {
+		log.Printf("CompleteEmail: claim of email(%s) by %s expired before completion\n", email.Id.Hex(), email.ClaimedBy)
+		return completion.status, utils.EmailClaimLost
+	}
+	if completion.setSentFlag {
+		return completion.status, applyEmailSentUpdate(ctx, email)
+	}
+	return completion.status, nil
+}
+
+// emailCompletion is the update storing the delivery result of a claimed email.
+type emailCompletion struct {
+	status   model.EmailStatus
+	updateOp bson.M
+	// setSentFlag is true when the email was delivered, dead emails leave the OnSent flag unset
+	// and their group is kept from being enqueued again by the dedupe keys.
+	setSentFlag bool
+}
+
+func newEmailCompletion(email *model.OutboxEmail, sendErr error, now time.Time) *emailCompletion {
+	setFields := bson.M{}
+	switch {
+	case sendErr == nil:
+		setFields["status"] = model.EmailSent
+		setFields["sentAt"] = now
+	case email.Attempts >= model.MaxEmailAttempts:
+		setFields["status"] = model.EmailDead
+		setFields["lastError"] = sendErr.Error()
+	default:
+		setFields["status"] = model.EmailFailed
+		setFields["lastError"] = sendErr.Error()
+		setFields["nextAttemptAt"] = now.Add(model.GetRetryDelay(email.Attempts))
+	}
+	status := setFields["status"].(model.EmailStatus)
+	unsetFields := bson.M{"claimedUntil": ""}
+	if email.Category == model.CategoryAccount && status != model.EmailFailed {
+		// account emails carry sign-in links which must not outlive the delivery
+		unsetFields["text"] = ""
+		unsetFields["html"] = ""
+	}
+	return &emailCompletion{
+		status:      status,
+		updateOp:    bson.M{"$set": setFields, "$unset": unsetFields},
+		setSentFlag: status == model.EmailSent,
+	}
+}
+
+// applyEmailSentUpdate sets the flag of OnSent once every email of the group is sent. Every
+// email completing the group counts after its own update and sets the flag, so concurrent
+// completions cannot all miss it.
+func applyEmailSentUpdate(ctx context.Context, email *model.OutboxEmail) error {
+	if email.OnSent == nil {
+		return nil
+	}
+	if email.Group != "" {
+		filter := bson.M{"group": email.Group, "status": bson.M{"$ne": model.EmailSent}}
+		unsent, err := GetCollection(EmailOutboxCollectionName).CountDocuments(ctx, filter)
+		if err != nil {
+			log.Printf("applyEmailSentUpdate: failed to count emails of group(%s): %v\n", email.Group, err)
+			return err
+		}
+		if unsent > 0 {
+			return nil
+		}
+	}
+	return setEmailSentFlag(ctx, email.OnSent)
+}
+
+// SetEmailSentFlag sets the flag without an email, e.g. when the recipients opted out.
+func SetEmailSentFlag(update *model.EmailSentUpdate) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	return setEmailSentFlag(ctx, update)
+}
+
+func setEmailSentFlag(ctx context.Context, update *model.EmailSentUpdate) error {
+	filter := bson.M{"_id": update.DocumentId}
+	updateOp := bson.M{"$set": bson.M{update.Field: true}}
+	result, err := GetCollection(update.Collection).UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("setEmailSentFlag: failed to set %s of %s(%s): %v\n", update.Field, update.Collection, update.DocumentId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		log.Printf("setEmailSentFlag: %s(%s) was not found\n", update.Collection, update.DocumentId.Hex())
+	}
+	return nil
+}
+
+// GetUserEmails returns the latest emails sent or queued for the user, newest first.
+func GetUserEmails(userId primitive.ObjectID, limit int64) ([]*model.OutboxEmail, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(EmailOutboxCollectionName)
+	findOptions := options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"userId": userId}, findOptions)
+	if err != nil {
+		log.Printf("Failed to find emails of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	emails := []*model.OutboxEmail{}
+	if err = cursor.All(ctx, &emails); err != nil {
+		log.Printf("Failed to decode emails of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	return emails, nil
+}
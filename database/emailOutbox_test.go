@@ -0,0 +1,106 @@
+package database
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"oysterProject/model"
+	"testing"
+	"time"
+)
+
+func TestEmailCompletionUntilDead(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sendErr := errors.New("mailer unavailable")
+	email := &model.OutboxEmail{
+		OnSent: &model.EmailSentUpdate{Collection: SessionCollectionName, Field: "emailWasSent"},
+	}
+	for attempts := 1; attempts <= model.MaxEmailAttempts; attempts++ {
+		email.Attempts = attempts
+		completion := newEmailCompletion(email, sendErr, now)
+		wantStatus := model.EmailFailed
+		if attempts == model.MaxEmailAttempts {
+			wantStatus = model.EmailDead
+		}
+		if completion.status != wantStatus {
+			t.Fatalf("attempt %d: status = %s, want %s", attempts, completion.status, wantStatus)
+		}
+		if completion.setSentFlag {
+			t.Errorf("attempt %d: %s email sets the sent flag", attempts, completion.status)
+		}
+		setFields := completion.updateOp["$set"].(bson.M)
+		if _, ok := setFields["sentAt"]; ok {
+			t.Errorf("attempt %d: %s email has sentAt", attempts, completion.status)
+		}
+	}
+}
+
+func TestEmailCompletion(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sendErr := errors.New("mailer unavailable")
+	tests := []struct {
+		name            string
+		email           *model.OutboxEmail
+		sendErr         error
+		wantStatus      model.EmailStatus
+		wantSentFlag    bool
+		wantBodyRemoved bool
+		wantNextAttempt time.Time
+	}{
+		{
+			name:         "sent",
+			email:        &model.OutboxEmail{Attempts: 1, Category: model.CategorySessionUpdates},
+			wantStatus:   model.EmailSent,
+			wantSentFlag: true,
+		},
+		{
+			name:            "failed",
+			email:           &model.OutboxEmail{Attempts: 3, Category: model.CategorySessionUpdates},
+			sendErr:         sendErr,
+			wantStatus:      model.EmailFailed,
+			wantNextAttempt: now.Add(4 * time.Minute),
+		},
+		{
+			name:            "account email sent",
+			email:           &model.OutboxEmail{Attempts: 1, Category: model.CategoryAccount},
+			wantStatus:      model.EmailSent,
+			wantSentFlag:    true,
+			wantBodyRemoved: true,
+		},
+		{
+			name:            "account email failed",
+			email:           &model.OutboxEmail{Attempts: 1, Category: model.CategoryAccount},
+			sendErr:         sendErr,
+			wantStatus:      model.EmailFailed,
+			wantNextAttempt: now.Add(time.Minute),
+		},
+		{
+			name:            "account email dead",
+			email:           &model.OutboxEmail{Attempts: model.MaxEmailAttempts, Category: model.CategoryAccount},
+			sendErr:         sendErr,
+			wantStatus:      model.EmailDead,
+			wantBodyRemoved: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			completion := newEmailCompletion(test.email, test.sendErr, now)
+			if completion.status != test.wantStatus {
+				t.Errorf("status = %s, want %s", completion.status, test.wantStatus)
+			}
+			if completion.setSentFlag != test.wantSentFlag {
+				t.Errorf("setSentFlag = %v, want %v", completion.setSentFlag, test.wantSentFlag)
+			}
+			unsetFields := completion.updateOp["$unset"].(bson.M)
+			_, textRemoved := unsetFields["text"]
+			_, htmlRemoved := unsetFields["html"]
+			if textRemoved != test.wantBodyRemoved || htmlRemoved != test.wantBodyRemoved {
+				t.Errorf("body removed = (%v, %v), want %v", textRemoved, htmlRemoved, test.wantBodyRemoved)
+			}
+			setFields := completion.updateOp["$set"].(bson.M)
+			nextAttemptAt, _ := setFields["nextAttemptAt"].(time.Time)
+			if !nextAttemptAt.Equal(test.wantNextAttempt) {
+				t.Errorf("nextAttemptAt = %v, want %v", nextAttemptAt, test.wantNextAttempt)
+			}
+		})
+	}
+}
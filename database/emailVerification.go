@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+// VerifyEmail marks the address as verified if it is still the address of the user.
+func VerifyEmail(userId primitive.ObjectID, email string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "email": email}
+	updateOp := bson.M{
+		"$set":   bson.M{"emailVerified": true},
+		"$unset": bson.M{"verificationEmailAt": ""},
+	}
+	result, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp, options.Update().SetCollation(emailCollation))
+	if err != nil {
+		log.Printf("Failed to verify email of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// TakeOverUnverifiedAccount verifies the address of an account confirmed by an identity provider
+// and drops the credentials set up before, since whoever registered the address could not prove
+// they own it.
+func TakeOverUnverifiedAccount(userId primitive.ObjectID, email string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "email": email, "emailVerified": bson.M{"$ne": true}}
+	updateOp := bson.M{
+		"$set":   bson.M{"emailVerified": true},
+		"$unset": bson.M{"verificationEmailAt": "", "password": "", "twoFactor": ""},
+	}
+	result, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp, options.Update().SetCollation(emailCollation))
+	if err != nil {
+		log.Printf("Failed to take over unverified account of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return DeleteUserAuthSessions(userId)
+}
+
+// ClaimVerificationEmail records that a verification email is sent now. It fails with
+// VerificationEmailThrottled when the previous one was sent less than throttle ago.
+func ClaimVerificationEmail(userId primitive.ObjectID, now time.Time, throttle time.Duration) (*model.User, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{
+		"_id":           userId,
+		"emailVerified": false,
+		"$or": bson.A{
+			bson.M{"verificationEmailAt": bson.M{"$exists": false}},
+			bson.M{"verificationEmailAt": bson.M{"$lte": now.Add(-throttle)}},
+		},
+	}
+	updateOp := bson.M{"$set": bson.M{"verificationEmailAt": now}}
+	var user model.User
+	err := GetCollection(UserCollectionName).FindOneAndUpdate(ctx, filter, updateOp, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Failed to claim verification email of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	current, err := GetUserByID(userId)
+	if err != nil {
+		return nil, err
+	}
+	if current.IsEmailVerified() {
+		return nil, utils.EmailAlreadyVerified
+	}
+	return nil, utils.VerificationEmailThrottled
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const (
+	// jobRunsRetention limits how long the job run history is kept.
+	jobRunsRetention = 30 * 24 * time.Hour
+	// notificationsRetention limits how long in-app notifications are kept.
+	notificationsRetention = 180 * 24 * time.Hour
+)
+
+// emailCollation compares emails case-insensitively. Queries on users.email must use it to be
+// served by the unique email index.
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+var collectionIndexes = map[string][]mongo.IndexModel{
+	UserCollectionName: {
+		// Emails are stored lower case, the collation also rejects duplicates written before
+		// normalisation. cmd/mergeDuplicateUsers merges those before the index is created.
+		{
+			Keys: bson.D{{"email", 1}},
+			Options: options.Index().SetUnique(true).
+				SetCollation(emailCollation).
+				SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}}),
+		},
+	},
+	SessionReminderCollectionName: {
+		{
+			Keys:    bson.D{{"sessionId", 1}, {"offsetMinutes", 1}, {"sessionTimeStart", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{"status", 1}, {"sendAt", 1}}},
+	},
+	JobRunCollectionName: {
+		{Keys: bson.D{{"jobName", 1}, {"startedAt", -1}}},
+		{
+			Keys:    bson.D{{"startedAt", 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(jobRunsRetention / time.Second)),
+		},
+	},
+	EmailOutboxCollectionName: {
+		{Keys: bson.D{{"status", 1}, {"nextAttemptAt", 1}}},
+		{Keys: bson.D{{"userId", 1}, {"createdAt", -1}}},
+		{Keys: bson.D{{"group", 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{"expiresAt", 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{
+			Keys: bson.D{{"dedupeKey", 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"dedupeKey": bson.M{"$type": "string"}}),
+		},
+	},
+	NotificationCollectionName: {
+		{Keys: bson.D{{"userId", 1}, {"createdAt", -1}}},
+		{Keys: bson.D{{"userId", 1}, {"read", 1}}},
+		{Keys: bson.D{{"userId", 1}, {"_id", 1}}},
+		{
+			Keys:    bson.D{{"createdAt", 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(notificationsRetention / time.Second)),
+		},
+		{
+			Keys: bson.D{{"dedupeKey", 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"dedupeKey": bson.M{"$type": "string"}}),
+		},
+	},
+	WebhookDeliveryCollectionName: {
+		{Keys: bson.D{{"status", 1}, {"nextAttemptAt", 1}}},
+		{Keys: bson.D{{"subscriptionId", 1}, {"createdAt", -1}}},
+		{
+			Keys: bson.D{{"subscriptionId", 1}, {"dedupeKey", 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"dedupeKey": bson.M{"$type": "string"}}),
+		},
+	},
+	PasswordResetTokenCollectionName: {
+		{Keys: bson.D{{"userId", 1}}},
+		{Keys: bson.D{{"expiresAt", 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	},
+	AuthSessionCollectionName: {
+		{Keys: bson.D{{"userId", 1}, {"lastSeenAt", -1}}},
+		{Keys: bson.D{{"publicId", 1}}, Options: options.Index().SetSparse(true)},
+	},
+	TwoFactorChallengeCollectionName: {
+		{Keys: bson.D{{"expiresAt", 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	},
+	EmailEventCollectionName: {
+		{Keys: bson.D{{"outboxEmailId", 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{"email", 1}, {"timestamp", -1}}},
+	},
+}
+
+// EnsureIndexes creates the indexes the application relies on. Existing indexes are kept.
+func EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for collectionName, indexes := range collectionIndexes {
+		_, err := GetCollection(collectionName).Indexes().CreateMany(ctx, indexes)
+		if mongo.IsDuplicateKeyError(err) {
+			// existing documents violate a unique index, the server keeps working without it
+			log.Printf("EnsureIndexes: unique index of %s was not created, run cmd/mergeDuplicateUsers: %v\n", collectionName, err)
+		} else if err != nil {
+			log.Printf("EnsureIndexes: failed to create indexes for %s: %v\n", collectionName, err)
+			return err
+		}
+	}
+	return nil
+}
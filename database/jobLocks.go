@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"time"
+)
+
+// AcquireJobLock takes the lease of the job for owner. The lease is free when it expired and
+// the job did not finish within minInterval, so instances whose schedules drift apart do not
+// run the job again right after another instance. A zero minInterval ignores the last run.
+func AcquireJobLock(jobName, owner string, ttl, minInterval time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobLockCollectionName)
+	now := time.Now().UTC()
+	filter := bson.M{"_id": jobName, "expiresAt": bson.M{"$lt": now}}
+	if minInterval > 0 {
+		filter["$or"] = bson.A{
+			bson.M{"lastFinishedAt": bson.M{"$exists": false}},
+			bson.M{"lastFinishedAt": bson.M{"$lte": now.Add(-minInterval)}},
+		}
+	}
+	updateOp := bson.M{"$set": bson.M{"owner": owner, "acquiredAt": now, "expiresAt": now.Add(ttl)}}
+	_, err := collection.UpdateOne(ctx, filter, updateOp, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	} else if err != nil {
+		log.Printf("Failed to acquire lock for job %s: %v\n", jobName, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// RenewJobLock extends the lease, false means the lease was lost to another owner.
+func RenewJobLock(jobName, owner string, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobLockCollectionName)
+	filter := bson.M{"_id": jobName, "owner": owner}
+	updateOp := bson.M{"$set": bson.M{"expiresAt": time.Now().UTC().Add(ttl)}}
+	result, err := collection.UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to renew lock for job %s: %v\n", jobName, err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// ReleaseJobLock ends the lease and records when the job finished.
+func ReleaseJobLock(jobName, owner string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobLockCollectionName)
+	now := time.Now().UTC()
+	filter := bson.M{"_id": jobName, "owner": owner}
+	updateOp := bson.M{"$set": bson.M{"expiresAt": now, "lastFinishedAt": now}}
+	if _, err := collection.UpdateOne(ctx, filter, updateOp); err != nil {
+		log.Printf("Failed to release lock for job %s: %v\n", jobName, err)
+		return err
+	}
+	return nil
+}
+
+func GetJobLock(jobName string) (*model.JobLock, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobLockCollectionName)
+	var jobLock model.JobLock
+	if err := collection.FindOne(ctx, bson.M{"_id": jobName}).Decode(&jobLock); err != nil {
+		handleFindError(err, jobName, "job lock")
+		return nil, err
+	}
+	return &jobLock, nil
+}
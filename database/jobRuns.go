@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+)
+
+func InsertJobRun(run *model.JobRun) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobRunCollectionName)
+	result, err := collection.InsertOne(ctx, run)
+	if err != nil {
+		log.Printf("Failed to save run of job %s: %v\n", run.JobName, err)
+		return err
+	}
+	run.Id = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func FinishJobRun(run *model.JobRun) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobRunCollectionName)
+	updateOp := bson.M{"$set": bson.M{
+		"status":     run.Status,
+		"finishedAt": run.FinishedAt,
+		"durationMs": run.DurationMs,
+		"counts":     run.Counts,
+		"error":      run.Error,
+	}}
+	if _, err := collection.UpdateByID(ctx, run.Id, updateOp); err != nil {
+		log.Printf("Failed to update run(%s) of job %s: %v\n", run.Id.Hex(), run.JobName, err)
+		return err
+	}
+	return nil
+}
+
+// GetJobRuns returns the latest runs first, an empty jobName returns runs of all jobs.
+func GetJobRuns(jobName string, limit int64) ([]*model.JobRun, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobRunCollectionName)
+	filter := bson.M{}
+	if jobName != "" {
+		filter["jobName"] = jobName
+	}
+	findOptions := options.Find().SetSort(bson.D{{"startedAt", -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		log.Printf("Failed to find job runs: %v\n", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	runs := []*model.JobRun{}
+	if err = cursor.All(ctx, &runs); err != nil {
+		log.Printf("Failed to decode job runs: %v\n", err)
+		return nil, err
+	}
+	return runs, nil
+}
+
+func GetLastJobRun(jobName string) (*model.JobRun, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(JobRunCollectionName)
+	findOptions := options.FindOne().SetSort(bson.D{{"startedAt", -1}})
+	var run model.JobRun
+	err := collection.FindOne(ctx, bson.M{"jobName": jobName}, findOptions).Decode(&run)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("Failed to find last run of job %s: %v\n", jobName, err)
+		return nil, err
+	}
+	return &run, nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"oysterProject/utils"
+	"time"
+)
+
+// Zones for offsets that are not whole hours, Etc/GMT zones exist for whole hours only.
+var fractionalOffsetTimeZones = map[int]string{
+	-570: "Pacific/Marquesas",
+	-210: "America/St_Johns",
+	210:  "Asia/Tehran",
+	270:  "Asia/Kabul",
+	330:  "Asia/Kolkata",
+	345:  "Asia/Kathmandu",
+	390:  "Asia/Yangon",
+	525:  "Australia/Eucla",
+	570:  "Australia/Darwin",
+	630:  "Australia/Lord_Howe",
+	765:  "Pacific/Chatham",
+}
+
+// MigrateOffsetTimeZones converts users saved with minute offsets to IANA time zone names.
+// Before the migration latestTimeZone and availability.timeZone kept the offset east of UTC
+// in minutes and availability times were stored in UTC. It is safe to run on every start.
+func MigrateOffsetTimeZones() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	filter := bson.M{"$or": bson.A{
+		bson.M{"latestTimeZone": bson.M{"$exists": true}},
+		bson.M{"availability.timeZone": bson.M{"$type": "number"}},
+	}}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		log.Printf("MigrateOffsetTimeZones: failed to find users: %v\n", err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	migratedCount := 0
+	for cursor.Next(ctx) {
+		var user bson.M
+		if err = cursor.Decode(&user); err != nil {
+			log.Printf("MigrateOffsetTimeZones: failed to decode user: %v\n", err)
+			return err
+		}
+		update := bson.M{"$unset": bson.M{"latestTimeZone": ""}}
+		setFields := bson.M{}
+		if offset, ok := toInt(user["latestTimeZone"]); ok {
+			setFields["timeZone"] = offsetToTimeZone(offset)
+		}
+		if availability, ok := user["availability"].(bson.A); ok {
+			setFields["availability"] = migrateAvailability(availability)
+		}
+		if len(setFields) > 0 {
+			update["$set"] = setFields
+		}
+		if _, err = collection.UpdateOne(ctx, bson.M{"_id": user["_id"]}, update); err != nil {
+			log.Printf("MigrateOffsetTimeZones: failed to update user(%v): %v\n", user["_id"], err)
+			return err
+		}
+		migratedCount++
+	}
+	if migratedCount > 0 {
+		log.Printf("MigrateOffsetTimeZones: %d users migrated to IANA time zones\n", migratedCount)
+	}
+	return cursor.Err()
+}
+
+func migrateAvailability(availabilities bson.A) bson.A {
+	var result bson.A
+	for _, item := range availabilities {
+		availability, ok := item.(bson.M)
+		if !ok {
+			continue
+		}
+		offset, isOffset := toInt(availability["timeZone"])
+		if !isOffset {
+			result = append(result, availability)
+			continue
+		}
+		weekday, _ := availability["weekday"].(string)
+		timeFrom, _ := availability["timeFrom"].(string)
+		timeTo, _ := availability["timeTo"].(string)
+		timeZone := offsetToTimeZone(offset)
+
+		// Old slots were generated on the UTC calendar, so the window is rebuilt in UTC
+		// on a reference week and moved to the new zone, which may change the weekday.
+		referenceDate := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		for referenceDate.Weekday() != utils.GetDayOfWeek(weekday) {
+			referenceDate = referenceDate.AddDate(0, 0, 1)
+		}
+		utcFrom, errFrom := time.Parse(utils.DateLayout, referenceDate.Format(time.DateOnly)+" "+timeFrom)
+		utcTo, errTo := time.Parse(utils.DateLayout, referenceDate.Format(time.DateOnly)+" "+timeTo)
+		if errFrom != nil || errTo != nil {
+			log.Printf("migrateAvailability: skipping malformed availability: %v\n", availability)
+			continue
+		}
+		location := utils.GetLocation(timeZone)
+		localFrom := utcFrom.In(location)
+		result = append(result, bson.M{
+			"weekday":  localFrom.Weekday().String()[:3],
+			"timeFrom": localFrom.Format(utils.TimeLayout),
+			"timeTo":   utcTo.In(location).Format(utils.TimeLayout),
+			"timeZone": timeZone,
+		})
+	}
+	return result
+}
+
+// offsetToTimeZone maps an offset east of UTC in minutes to an IANA zone. Whole hours map
+// to the Etc/GMT zones whose sign is inverted by definition.
+func offsetToTimeZone(offset int) string {
+	if offset == 0 {
+		return "UTC"
+	}
+	if offset%60 == 0 {
+		return fmt.Sprintf("Etc/GMT%+d", -offset/60)
+	}
+	if timeZone, ok := fractionalOffsetTimeZones[offset]; ok {
+		return timeZone
+	}
+	log.Printf("offsetToTimeZone: no time zone for offset %d, UTC is used\n", offset)
+	return "UTC"
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -27,8 +27,12 @@ func CreateUser(user *model.User) (primitive.ObjectID, error) {
 	collection := GetCollection(UserCollectionName)
 	ctx, cancel := withTimeout(context.Background())
 	defer cancel()
+	user.Email = utils.NormalizeEmail(user.Email)
 	doc, err := collection.InsertOne(ctx, user)
-	if err != nil {
+	if mongo.IsDuplicateKeyError(err) {
+		log.Printf("User with email(%s) already exists\n", user.Email)
+		return primitive.ObjectID{}, utils.UserAlreadyExists
+	} else if err != nil {
 		log.Printf("Error inserting user: %v\n", err)
 		return primitive.ObjectID{}, err
 	}
@@ -81,8 +85,9 @@ func getOffsetAndLimit(params url.Values) (int, int, error) {
 
 func getFilterForMentorList(params url.Values, userId primitive.ObjectID) (bson.M, error) {
 	filter := bson.M{
-		"isApproved": true,
-		"isPublic":   true,
+		"isApproved":    true,
+		"isPublic":      true,
+		"emailVerified": bson.M{"$ne": false},
 	}
 
 	for key, values := range params {
@@ -122,9 +127,10 @@ func hasExtraKeys(keys map[string][]string) bool {
 
 func getFilterForTopMentorList() bson.M {
 	return bson.M{
-		"isApproved":  true,
-		"isTopMentor": true,
-		"isPublic":    true,
+		"isApproved":    true,
+		"isTopMentor":   true,
+		"isPublic":      true,
+		"emailVerified": bson.M{"$ne": false},
 	}
 }
 
@@ -338,7 +344,9 @@ func UpdateAndGetUser(user *model.User, id primitive.ObjectID) (*model.User, err
 	ctx, cancel := withTimeout(context.Background())
 	defer cancel()
 	_, err := collection.UpdateOne(ctx, filter, updateOp)
-	if err != nil {
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, utils.UserAlreadyExists
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -490,9 +498,9 @@ func GetUserByEmail(email string) (*model.User, error) {
 	usersCollection := GetCollection(UserCollectionName)
 	ctx, cancel := withTimeout(context.Background())
 	defer cancel()
-	filter := bson.M{"email": email}
+	filter := bson.M{"email": utils.NormalizeEmail(email)}
 	var user model.User
-	err := usersCollection.FindOne(ctx, filter).Decode(&user)
+	err := usersCollection.FindOne(ctx, filter, options.FindOne().SetCollation(emailCollation)).Decode(&user)
 	return &user, err
 }
 
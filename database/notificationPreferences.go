@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"time"
+)
+
+// GetNotificationPreferences returns nil preferences, i.e. everything enabled, for users who
+// never changed them.
+func GetNotificationPreferences(userId primitive.ObjectID) (*model.NotificationPreferences, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(NotificationPreferencesCollectionName)
+	var preferences model.NotificationPreferences
+	err := collection.FindOne(ctx, bson.M{"_id": userId}).Decode(&preferences)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("Failed to find notification preferences of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// UpdateNotificationPreferences changes only the given categories and channels.
+func UpdateNotificationPreferences(userId primitive.ObjectID, categories map[model.NotificationCategory]map[model.NotificationChannel]bool) (*model.NotificationPreferences, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(NotificationPreferencesCollectionName)
+	setFields := bson.M{"updatedAt": time.Now().UTC()}
+	for category, channels := range categories {
+		for channel, enabled := range channels {
+			setFields["categories."+string(category)+"."+string(channel)] = enabled
+		}
+	}
+	findOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var preferences model.NotificationPreferences
+	err := collection.FindOneAndUpdate(ctx, bson.M{"_id": userId}, bson.M{"$set": setFields}, findOptions).Decode(&preferences)
+	if err != nil {
+		log.Printf("Failed to update notification preferences of user(%s): %v\n", userId.Hex(), err)
+		return nil, err
+	}
+	return &preferences, nil
+}
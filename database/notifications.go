@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"time"
+)
+
+// InsertNotification stores the notification. A notification whose DedupeKey already exists
+// is not added again.
+func InsertNotification(notification *model.Notification) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(NotificationCollectionName)
+	notification.Read = false
+	notification.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if notification.DedupeKey == "" {
+		result, err := collection.InsertOne(ctx, notification)
+		if err != nil {
+			log.Printf("InsertNotification: failed to insert notification for user(%s): %v\n", notification.UserId.Hex(), err)
+			return err
+		}
+		notification.Id = result.InsertedID.(primitive.ObjectID)
+		return nil
+	}
+	filter := bson.M{"dedupeKey": notification.DedupeKey}
+	updateOp := bson.M{"$setOnInsert": notification}
+	_, err := collection.UpdateOne(ctx, filter, updateOp, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	} else if err != nil {
+		log.Printf("InsertNotification: failed to upsert notification(%s): %v\n", notification.DedupeKey, err)
+		return err
+	}
+	return nil
+}
+
+// GetNotifications returns the latest notifications of the user, newest first.
+func GetNotifications(userId primitive.ObjectID, unreadOnly bool, limit int64) ([]*model.Notification, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"userId": userId}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	findOptions := options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(limit)
+	return findNotifications(ctx, filter, findOptions)
+}
+
+// GetLatestNotificationId returns the id of the newest notification of the user, or NilObjectID
+// when there is none.
+func GetLatestNotificationId(ctx context.Context, userId primitive.ObjectID) (primitive.ObjectID, error) {
+	findOptions := options.FindOne().SetSort(bson.D{{"_id", -1}}).SetProjection(bson.M{"_id": 1})
+	var notification model.Notification
+	err := GetCollection(NotificationCollectionName).FindOne(ctx, bson.M{"userId": userId}, findOptions).Decode(&notification)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, nil
+	} else if err != nil {
+		log.Printf("Failed to find latest notification of user(%s): %v\n", userId.Hex(), err)
+		return primitive.NilObjectID, err
+	}
+	return notification.Id, nil
+}
+
+// GetNotificationsAfter returns the notifications stored after the one with afterId, oldest first.
+func GetNotificationsAfter(ctx context.Context, userId, afterId primitive.ObjectID) ([]*model.Notification, error) {
+	filter := bson.M{"userId": userId, "_id": bson.M{"$gt": afterId}}
+	findOptions := options.Find().SetSort(bson.D{{"_id", 1}})
+	return findNotifications(ctx, filter, findOptions)
+}
+
+func findNotifications(ctx context.Context, filter bson.M, findOptions *options.FindOptions) ([]*model.Notification, error) {
+	cursor, err := GetCollection(NotificationCollectionName).Find(ctx, filter, findOptions)
+	if err != nil {
+		log.Printf("Failed to find notifications: %v\n", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	notifications := []*model.Notification{}
+	if err = cursor.All(ctx, &notifications); err != nil {
+		log.Printf("Failed to decode notifications: %v\n", err)
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func CountUnreadNotifications(ctx context.Context, userId primitive.ObjectID) (int64, error) {
+	count, err := GetCollection(NotificationCollectionName).CountDocuments(ctx, bson.M{"userId": userId, "read": false})
+	if err != nil {
+		log.Printf("Failed to count unread notifications of user(%s): %v\n", userId.Hex(), err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkNotificationRead returns mongo.ErrNoDocuments when the user has no such notification.
+func MarkNotificationRead(userId, notificationId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": notificationId, "userId": userId}
+	updateOp := bson.M{"$set": bson.M{"read": true, "readAt": time.Now().UTC()}}
+	result, err := GetCollection(NotificationCollectionName).UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to mark notification(%s) read: %v\n", notificationId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func MarkAllNotificationsRead(userId primitive.ObjectID) (int64, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"userId": userId, "read": false}
+	updateOp := bson.M{"$set": bson.M{"read": true, "readAt": time.Now().UTC()}}
+	result, err := GetCollection(NotificationCollectionName).UpdateMany(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to mark notifications of user(%s) read: %v\n", userId.Hex(), err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"oysterProject/model"
+	"time"
+)
+
+func SavePasswordResetToken(token *model.PasswordResetToken) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	if _, err := GetCollection(PasswordResetTokenCollectionName).InsertOne(ctx, token); err != nil {
+		log.Printf("Failed to save password reset token for user(%s): %v\n", token.UserId.Hex(), err)
+		return err
+	}
+	return nil
+}
+
+// ConsumePasswordResetToken deletes the token and returns it, so a token is used only once
+// even with concurrent requests. The TTL index removes expired tokens with a delay, they are
+// filtered out here.
+func ConsumePasswordResetToken(tokenHash string, now time.Time) (*model.PasswordResetToken, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": tokenHash, "expiresAt": bson.M{"$gt": now}}
+	var token model.PasswordResetToken
+	if err := GetCollection(PasswordResetTokenCollectionName).FindOneAndDelete(ctx, filter).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ResetPassword sets the new password, invalidates the other reset links of the user and signs
+// the user out everywhere.
+func ResetPassword(userId primitive.ObjectID, newPassword string) error {
+	if err := updatePassword(userId, newPassword); err != nil {
+		log.Printf("Failed to reset password of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	if _, err := GetCollection(PasswordResetTokenCollectionName).DeleteMany(ctx, bson.M{"userId": userId}); err != nil {
+		log.Printf("Failed to delete password reset tokens of user(%s): %v\n", userId.Hex(), err)
+	}
+	return DeleteUserAuthSessions(userId)
+}
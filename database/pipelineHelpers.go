@@ -111,16 +111,14 @@ func GetUserBestMentorsPipeline(idToFind primitive.ObjectID) bson.A {
 	return pipeline
 }
 
-func GetSessionsForNotificationPipeline(filterTimeGt, filterTimeLte time.Time) bson.A {
+func GetSessionNotificationPipeline(sessionId primitive.ObjectID, sessionTimeStart time.Time) bson.A {
 	pipeline := bson.A{
 		bson.D{
 			{"$match",
 				bson.D{
+					{"_id", sessionId},
 					{"sessionStatus", model.Confirmed},
-					{"sessionTimeStart", bson.D{
-						{"$gt", filterTimeGt},
-						{"$lte", filterTimeLte},
-					}},
+					{"sessionTimeStart", sessionTimeStart},
 				},
 			},
 		},
@@ -159,6 +157,8 @@ func GetSessionsForNotificationPipeline(filterTimeGt, filterTimeLte time.Time) b
 					{"menteeEmail", "$mentee.email"},
 					{"mentorName", "$mentor.name"},
 					{"mentorEmail", "$mentor.email"},
+					{"menteeLanguage", "$mentee.preferredLanguage"},
+					{"mentorLanguage", "$mentor.preferredLanguage"},
 				},
 			},
 		},
@@ -211,6 +211,8 @@ func GetSessionsForReviewNotificationPipeline() bson.A {
 					{"menteeEmail", "$mentee.email"},
 					{"mentorName", "$mentor.name"},
 					{"mentorEmail", "$mentor.email"},
+					{"menteeLanguage", "$mentee.preferredLanguage"},
+					{"mentorLanguage", "$mentor.preferredLanguage"},
 				},
 			},
 		},
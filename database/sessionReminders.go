@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"time"
+)
+
+const reminderClaimTimeout = 5 * time.Minute
+
+// CreateSessionReminders adds the missing reminders of confirmed sessions starting in (timeFrom, timeTo].
+// Existing reminders are left untouched, so the function can run on every instance and after restarts.
+// A reminder whose time had passed when the session was confirmed, e.g. the day-before reminder
+// of a session booked for today, is skipped. Reminders missed during downtime are still created
+// and sent while the session is in the future.
+func CreateSessionReminders(ctx context.Context, timeFrom, timeTo time.Time) (int64, error) {
+	sessionCollection := GetCollection(SessionCollectionName)
+	filter := bson.M{
+		"sessionStatus":    model.Confirmed,
+		"sessionTimeStart": bson.M{"$gt": timeFrom, "$lte": timeTo},
+	}
+	cursor, err := sessionCollection.Find(ctx, filter)
+	if err != nil {
+		log.Printf("CreateSessionReminders: failed to find sessions: %v\n", err)
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	var sessions []*model.Session
+	if err = cursor.All(ctx, &sessions); err != nil {
+		log.Printf("CreateSessionReminders: failed to decode sessions: %v\n", err)
+		return 0, err
+	}
+
+	var models []mongo.WriteModel
+	now := time.Now().UTC()
+	for _, session := range sessions {
+		for _, offset := range model.SessionReminderOffsets {
+			sendAt := session.SessionTimeStart.Add(-offset)
+			if session.ConfirmedAt != nil && session.ConfirmedAt.After(sendAt) {
+				continue
+			}
+			reminderFilter := bson.M{
+				"sessionId":        session.SessionId,
+				"offsetMinutes":    int(offset / time.Minute),
+				"sessionTimeStart": session.SessionTimeStart,
+			}
+			reminder := bson.M{"$setOnInsert": bson.M{
+				"sendAt":    sendAt,
+				"status":    model.ReminderPending,
+				"attempts":  0,
+				"createdAt": now,
+			}}
+			models = append(models, mongo.NewUpdateOneModel().SetFilter(reminderFilter).SetUpdate(reminder).SetUpsert(true))
+		}
+	}
+	if len(models) == 0 {
+		return 0, nil
+	}
+	result, err := GetCollection(SessionReminderCollectionName).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		log.Printf("CreateSessionReminders: failed to upsert reminders: %v\n", err)
+		return 0, err
+	}
+	return result.UpsertedCount, nil
+}
+
+// ClaimDueSessionReminder atomically hands one due reminder to the owner. Reminders whose claim
+// expired because the owner stopped are claimed again. The closest offset is claimed first, so
+// catching up after downtime sends one reminder per session instead of all missed ones.
+func ClaimDueSessionReminder(ctx context.Context, owner string, now time.Time) (*model.SessionReminder, error) {
+	collection := GetCollection(SessionReminderCollectionName)
+	filter := bson.M{
+		"sendAt":           bson.M{"$lte": now},
+		"sessionTimeStart": bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"status": model.ReminderPending},
+			bson.M{"status": model.ReminderProcessing, "claimedUntil": bson.M{"$lt": now}},
+		},
+	}
+	updateOp := bson.M{
+		"$set": bson.M{
+			"status":       model.ReminderProcessing,
+			"claimedBy":    owner,
+			"claimedUntil": now.Add(reminderClaimTimeout),
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{"offsetMinutes", 1}, {"sendAt", 1}}).
+		SetReturnDocument(options.After)
+	var reminder model.SessionReminder
+	err := collection.FindOneAndUpdate(ctx, filter, updateOp, findOptions).Decode(&reminder)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("ClaimDueSessionReminder: failed to claim reminder: %v\n", err)
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// CompleteSessionReminder stores the final status of a claimed reminder. The claim owner is
+// part of the filter, so a worker whose claim expired cannot overwrite the new owner's result.
+func CompleteSessionReminder(ctx context.Context, reminder *model.SessionReminder, status model.ReminderStatus) error {
+	collection := GetCollection(SessionReminderCollectionName)
+	filter := bson.M{"_id": reminder.Id, "claimedBy": reminder.ClaimedBy, "status": model.ReminderProcessing}
+	setFields := bson.M{"status": status}
+	if status == model.ReminderSent {
+		setFields["sentAt"] = time.Now().UTC()
+	}
+	updateOp := bson.M{"$set": setFields, "$unset": bson.M{"claimedUntil": ""}}
+	if _, err := collection.UpdateOne(ctx, filter, updateOp); err != nil {
+		log.Printf("CompleteSessionReminder: failed to update reminder(%s): %v\n", reminder.Id.Hex(), err)
+		return err
+	}
+	if status == model.ReminderSent {
+		return skipEarlierSessionReminders(ctx, reminder)
+	}
+	return nil
+}
+
+// skipEarlierSessionReminders drops pending reminders with a bigger offset which were missed.
+func skipEarlierSessionReminders(ctx context.Context, reminder *model.SessionReminder) error {
+	collection := GetCollection(SessionReminderCollectionName)
+	filter := bson.M{
+		"sessionId":        reminder.SessionId,
+		"sessionTimeStart": reminder.SessionTimeStart,
+		"offsetMinutes":    bson.M{"$gt": reminder.OffsetMinutes},
+		"status":           model.ReminderPending,
+	}
+	updateOp := bson.M{"$set": bson.M{"status": model.ReminderSkipped}}
+	if _, err := collection.UpdateMany(ctx, filter, updateOp); err != nil {
+		log.Printf("skipEarlierSessionReminders: failed to update reminders of session(%s): %v\n", reminder.SessionId.Hex(), err)
+		return err
+	}
+	return nil
+}
+
+// GetSessionNotification returns the session with participant contacts when it is still
+// confirmed and starts at sessionTimeStart, otherwise nil.
+func GetSessionNotification(ctx context.Context, sessionId primitive.ObjectID, sessionTimeStart time.Time) (*model.SessionNotification, error) {
+	collection := GetCollection(SessionCollectionName)
+	cursor, err := collection.Aggregate(ctx, GetSessionNotificationPipeline(sessionId, sessionTimeStart))
+	if err != nil {
+		log.Printf("GetSessionNotification: failed to find session(%s): %v\n", sessionId.Hex(), err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var sessions []*model.SessionNotification
+	if err = cursor.All(ctx, &sessions); err != nil {
+		log.Printf("GetSessionNotification: failed to decode session(%s): %v\n", sessionId.Hex(), err)
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return sessions[0], nil
+}
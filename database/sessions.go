@@ -10,11 +10,12 @@ import (
 	"log"
 	"oysterProject/model"
 	"oysterProject/utils"
+	"time"
 )
 
-func CreateSession(session model.Session) (*model.SessionResponse, error) {
-	mentorMenteeChan := make(chan []*model.UserImage)
-	errChan := make(chan error)
+func CreateSession(session model.Session, mentor *model.User) (*model.SessionResponse, error) {
+	mentorMenteeChan := make(chan []*model.UserImage, 1)
+	errChan := make(chan error, 1)
 	go func() {
 		mentorMenteeImages, err := GetUserImages([]primitive.ObjectID{session.MentorId, session.MenteeId})
 		if err != nil {
@@ -24,17 +25,30 @@ func CreateSession(session model.Session) (*model.SessionResponse, error) {
 		mentorMenteeChan <- mentorMenteeImages
 	}()
 
-	ctx, cancel := withTimeout(context.Background())
-	defer cancel()
 	collection := GetCollection(SessionCollectionName)
-	defer cancel()
-	doc, err := collection.InsertOne(ctx, session)
+	err := withMentorBookingLock(session.MentorId, func(ctx context.Context) error {
+		blockedTimeRange := session.GetBlockedTimeRange(*session.SessionTimeStart, *session.SessionTimeEnd)
+		if err := checkMentorSlotIsFree(ctx, session.MentorId, blockedTimeRange, primitive.NilObjectID); err != nil {
+			return err
+		}
+		if err := checkMentorBookingLimits(ctx, mentor, *session.SessionTimeStart, primitive.NilObjectID); err != nil {
+			return err
+		}
+		if err := checkMenteePendingSessions(ctx, mentor, session.MenteeId); err != nil {
+			return err
+		}
+		doc, err := collection.InsertOne(ctx, session)
+		if err != nil {
+			log.Printf("Error creating session: %v\n", err)
+			return err
+		}
+		session.SessionId = doc.InsertedID.(primitive.ObjectID)
+		return nil
+	})
 	if err != nil {
-		log.Printf("Error creating session: %v\n", err)
 		return nil, err
 	}
-	session.SessionId = doc.InsertedID.(primitive.ObjectID)
-	log.Printf("Session(menteeId: %s, mentorId: %s, sessionId:%s) created successfully\n", session.MenteeId, session.MentorId, doc.InsertedID)
+	log.Printf("Session(menteeId: %s, mentorId: %s, sessionId:%s) created successfully\n", session.MenteeId, session.MentorId, session.SessionId)
 	var mentorMenteeInfo []*model.UserImage
 	select {
 	case mentorMenteeFromChan := <-mentorMenteeChan:
@@ -47,39 +61,12 @@ func CreateSession(session model.Session) (*model.SessionResponse, error) {
 	return createSessionResponse(mentorMenteeInfo, &session)
 }
 
-func GetSession(sessionId string) (*model.SessionResponse, error) {
-	ctx, cancel := withTimeout(context.Background())
-	defer cancel()
-	collection := GetCollection(SessionCollectionName)
-	idToFind, _ := primitive.ObjectIDFromHex(sessionId)
-	filter := bson.M{"_id": idToFind}
-	var session model.Session
-	err := collection.FindOne(ctx, filter).Decode(&session)
-	if err != nil {
-		handleFindError(err, sessionId, "session")
-		return nil, err
-	}
-
+func PrepareSessionResponse(session *model.Session) (*model.SessionResponse, error) {
 	mentorMenteeInfo, err := GetUserImages([]primitive.ObjectID{session.MentorId, session.MenteeId})
 	if err != nil {
 		return nil, err
 	}
-	return createSessionResponse(mentorMenteeInfo, &session)
-}
-
-func GetMentorMenteeIdsBySessionId(sessionId string) (*model.Session, error) {
-	ctx, cancel := withTimeout(context.Background())
-	defer cancel()
-	collection := GetCollection(SessionCollectionName)
-	idToFind, _ := primitive.ObjectIDFromHex(sessionId)
-	filter := bson.M{"_id": idToFind}
-	var session model.Session
-	err := collection.FindOne(ctx, filter).Decode(&session)
-	if err != nil {
-		handleFindError(err, sessionId, "session")
-		return nil, err
-	}
-	return &session, nil
+	return createSessionResponse(mentorMenteeInfo, session)
 }
 
 func createSessionResponse(mentorMenteeInfo []*model.UserImage, session *model.Session) (*model.SessionResponse, error) {
@@ -109,6 +96,8 @@ func createSessionResponse(mentorMenteeInfo []*model.UserImage, session *model.S
 		NewSessionTimeStart: session.NewSessionTimeStart,
 		NewSessionTimeEnd:   session.NewSessionTimeEnd,
 		RequestFromMentee:   session.RequestFromMentee,
+		SessionTypeId:       session.SessionTypeId,
+		DurationMinutes:     int(session.GetDuration() / time.Minute),
 		SessionStatus:       session.SessionStatus,
 		Status:              session.Status,
 		StatusForMentee:     session.StatusForMentee,
@@ -117,6 +106,10 @@ func createSessionResponse(mentorMenteeInfo []*model.UserImage, session *model.S
 		MeetingLink:         session.MeetingLink,
 		MenteeReview:        session.MenteeReview,
 		MenteeRating:        session.MenteeRating,
+		CancellationReason:  session.CancellationReason,
+		CanceledBy:          session.CanceledBy,
+		CanceledAt:          session.CanceledAt,
+		Sequence:            session.Sequence,
 	}, nil
 }
 
@@ -138,23 +131,37 @@ func GetUserSessions(userId primitive.ObjectID, asMentor bool) ([]*model.Session
 	return decodeSessions(cursor, true)
 }
 
-func GetUserUpcomingSessions(userId primitive.ObjectID, asMentor bool) ([]*model.SessionResponse, error) {
+// GetMentorBookedSessions returns active sessions of the mentor whose time including buffers may touch [timeFrom, timeTo).
+func GetMentorBookedSessions(mentorId primitive.ObjectID, timeFrom, timeTo time.Time) ([]*model.Session, error) {
 	ctx, cancel := withTimeout(context.Background())
 	defer cancel()
+	return findMentorBookedSessions(ctx, mentorId, timeFrom, timeTo)
+}
+
+func findMentorBookedSessions(ctx context.Context, mentorId primitive.ObjectID, timeFrom, timeTo time.Time) ([]*model.Session, error) {
 	collection := GetCollection(SessionCollectionName)
-	filter := buildSessionFilter(userId, asMentor)
-	filter["sessionStatus"] = bson.M{"$lte": model.Confirmed}
-	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{"sessionTimeStart", 1}})
-	cursor, err := collection.Find(ctx, filter, findOptions)
-	if errors.Is(err, mongo.ErrNoDocuments) {
-		return nil, nil
-	} else if err != nil {
-		log.Printf("Failed to find sessions for mentee(%s): %v\n", userId.Hex(), err)
+	maxBuffer := model.MaxSessionBufferMinutes * time.Minute
+	searchFrom, searchTo := timeFrom.Add(-maxBuffer), timeTo.Add(maxBuffer)
+	filter := bson.M{
+		"mentorId":      mentorId,
+		"sessionStatus": bson.M{"$lte": model.Confirmed},
+		"$or": bson.A{
+			bson.M{"sessionTimeStart": bson.M{"$lt": searchTo}, "sessionTimeEnd": bson.M{"$gt": searchFrom}},
+			bson.M{"newSessionTimeStart": bson.M{"$lt": searchTo}, "newSessionTimeEnd": bson.M{"$gt": searchFrom}},
+		},
+	}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Failed to find booked sessions for mentor(%s): %v\n", mentorId.Hex(), err)
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-	return decodeSessions(cursor, false)
+	defer cursor.Close(ctx)
+	var sessions []*model.Session
+	if err = cursor.All(ctx, &sessions); err != nil {
+		log.Printf("Failed to decode booked sessions for mentor(%s): %v\n", mentorId.Hex(), err)
+		return nil, err
+	}
+	return sessions, nil
 }
 
 func buildSessionFilter(userId primitive.ObjectID, asMentor bool) bson.M {
@@ -190,34 +197,63 @@ func decodeSessions(cursor *mongo.Cursor, withImage bool) ([]*model.SessionRespo
 	return sessions, nil
 }
 
-func RescheduleSession(session model.Session) (*model.SessionResponse, error) {
-	filter := bson.M{"_id": session.SessionId}
+func GetSessionByID(sessionId primitive.ObjectID) (*model.Session, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(SessionCollectionName)
+	filter := bson.M{"_id": sessionId}
+	var session model.Session
+	err := collection.FindOne(ctx, filter).Decode(&session)
+	if err != nil {
+		handleFindError(err, sessionId.Hex(), "session")
+		return nil, err
+	}
+	return &session, nil
+}
+
+func RescheduleSession(session *model.Session, role model.SessionRole, mentor *model.User, newSessionTimeStart, newSessionTimeEnd *time.Time) (*model.SessionResponse, error) {
+	newStatus := model.GetRescheduleStatus(role)
+	if err := session.SessionStatus.CanTransitionTo(newStatus, role); err != nil {
+		return nil, err
+	}
+	filter := bson.M{"_id": session.SessionId, "sessionStatus": session.SessionStatus}
 	updateOp := bson.M{
 		"$set": bson.M{
-			"newSessionTimeStart": session.NewSessionTimeStart,
-			"newSessionTimeEnd":   session.NewSessionTimeEnd,
-			"sessionStatus":       session.SessionStatus,
+			"newSessionTimeStart": newSessionTimeStart,
+			"newSessionTimeEnd":   newSessionTimeEnd,
+			"sessionStatus":       newStatus,
 		},
+		"$inc": bson.M{"sequence": 1},
 	}
-	return updateSessionAndPrepareResponse(filter, updateOp)
+	var sessionResponse *model.SessionResponse
+	err := withMentorBookingLock(session.MentorId, func(ctx context.Context) error {
+		blockedTimeRange := session.GetBlockedTimeRange(*newSessionTimeStart, *newSessionTimeEnd)
+		if err := checkMentorSlotIsFree(ctx, session.MentorId, blockedTimeRange, session.SessionId); err != nil {
+			return err
+		}
+		if err := checkMentorBookingLimits(ctx, mentor, *newSessionTimeStart, session.SessionId); err != nil {
+			return err
+		}
+		var err error
+		sessionResponse, err = updateSessionStatusAndPrepareResponse(filter, updateOp)
+		return err
+	})
+	return sessionResponse, err
 }
 
-func ConfirmSession(sessionId string) (*model.SessionResponse, error) {
-	sessionIdObj, _ := primitive.ObjectIDFromHex(sessionId)
-	filter := bson.M{"_id": sessionIdObj}
-
-	session, err := GetSession(sessionId)
-	if err != nil {
+func ConfirmSession(session *model.Session, role model.SessionRole) (*model.SessionResponse, error) {
+	if err := session.SessionStatus.CanTransitionTo(model.Confirmed, role); err != nil {
 		return nil, err
 	}
+	filter := bson.M{"_id": session.SessionId, "sessionStatus": session.SessionStatus}
 	var updateOp bson.M
 	if session.SessionStatus == model.PendingByMentor {
 		updateOp = bson.M{
 			"$set": bson.M{
-				"sessionTimeStart": session.SessionTimeStart,
-				"sessionTimeEnd":   session.SessionTimeEnd,
-				"sessionStatus":    model.Confirmed,
+				"sessionStatus": model.Confirmed,
+				"confirmedAt":   time.Now().UTC(),
 			},
+			"$inc": bson.M{"sequence": 1},
 		}
 	} else {
 		updateOp = bson.M{
@@ -225,33 +261,53 @@ func ConfirmSession(sessionId string) (*model.SessionResponse, error) {
 				"sessionTimeStart": session.NewSessionTimeStart,
 				"sessionTimeEnd":   session.NewSessionTimeEnd,
 				"sessionStatus":    model.Confirmed,
+				"confirmedAt":      time.Now().UTC(),
 			},
 			"$unset": bson.M{
 				"newSessionTimeStart": "",
 				"newSessionTimeEnd":   "",
 			},
+			"$inc": bson.M{"sequence": 1},
 		}
 	}
 
-	return updateSessionAndPrepareResponse(filter, updateOp)
+	return updateSessionStatusAndPrepareResponse(filter, updateOp)
 }
 
-func CancelSession(sessionId, userId primitive.ObjectID) (*model.SessionResponse, error) {
-	filter := bson.M{"_id": sessionId}
-
-	user, err := GetUserByID(userId)
-	if err != nil {
-		log.Printf("CancelSession: Failed to find user(%s) err: %v\n", userId, err)
+func CancelSession(session *model.Session, role model.SessionRole, cancellation *model.SessionCancellation) (*model.SessionResponse, error) {
+	newStatus := model.GetCancelStatus(role)
+	if err := session.SessionStatus.CanTransitionTo(newStatus, role); err != nil {
 		return nil, err
 	}
-	var updateOp bson.M
-	if user.AsMentor {
-		updateOp = bson.M{"$set": bson.M{"sessionStatus": model.CanceledByMentor}}
-	} else {
-		updateOp = bson.M{"$set": bson.M{"sessionStatus": model.CanceledByMentee}}
+	canceledBy := session.MenteeId
+	if role == model.MentorRole {
+		canceledBy = session.MentorId
+	}
+	filter := bson.M{"_id": session.SessionId, "sessionStatus": session.SessionStatus}
+	setFields := bson.M{
+		"sessionStatus": newStatus,
+		"canceledBy":    canceledBy,
+		"canceledAt":    time.Now(),
+	}
+	if cancellation.Reason != "" {
+		setFields["cancellationReason"] = cancellation.Reason
+	}
+	updateOp := bson.M{
+		"$set": setFields,
+		"$inc": bson.M{"sequence": 1},
 	}
 
-	return updateSessionAndPrepareResponse(filter, updateOp)
+	return updateSessionStatusAndPrepareResponse(filter, updateOp)
+}
+
+// updateSessionStatusAndPrepareResponse expects the filter to contain the status the
+// transition was validated against, so a concurrent status change makes the update miss.
+func updateSessionStatusAndPrepareResponse(filter bson.M, updateOp bson.M) (*model.SessionResponse, error) {
+	response, err := updateSessionAndPrepareResponse(filter, updateOp)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, utils.SessionStatusChanged
+	}
+	return response, err
 }
 
 func updateSessionAndPrepareResponse(filter bson.M, updateOp bson.M) (*model.SessionResponse, error) {
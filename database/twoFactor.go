@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+// StartTwoFactorEnrollment stores a new secret which is not enforced until it is confirmed.
+// Enrolling again before the confirmation replaces the secret.
+func StartTwoFactorEnrollment(userId primitive.ObjectID, secret string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "twoFactor.enabled": bson.M{"$ne": true}}
+	updateOp := bson.M{"$set": bson.M{"twoFactor": &model.TwoFactorAuth{Secret: secret}}}
+	result, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to start two-factor enrollment of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return utils.TwoFactorAlreadyEnabled
+	}
+	return nil
+}
+
+// EnableTwoFactor confirms the enrollment with the time step of the first valid code.
+func EnableTwoFactor(userId primitive.ObjectID, step int64, recoveryCodeHashes []string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "twoFactor.enabled": false}
+	updateOp := bson.M{"$set": bson.M{
+		"twoFactor.enabled":       true,
+		"twoFactor.enabledAt":     time.Now().UTC(),
+		"twoFactor.recoveryCodes": recoveryCodeHashes,
+		"twoFactor.lastUsedStep":  step,
+	}}
+	result, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to enable two-factor authentication of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return utils.TwoFactorAlreadyEnabled
+	}
+	return nil
+}
+
+func DisableTwoFactor(userId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	updateOp := bson.M{"$unset": bson.M{"twoFactor": ""}}
+	if _, err := GetCollection(UserCollectionName).UpdateOne(ctx, bson.M{"_id": userId}, updateOp); err != nil {
+		log.Printf("Failed to disable two-factor authentication of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code. It fails for a step which is not newer
+// than the last one, so a code cannot be used twice.
+func UseTOTPStep(userId primitive.ObjectID, step int64) (bool, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true, "twoFactor.lastUsedStep": bson.M{"$lt": step}}
+	updateOp := bson.M{"$set": bson.M{"twoFactor.lastUsedStep": step}}
+	result, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to save two-factor step of user(%s): %v\n", userId.Hex(), err)
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// UseRecoveryCode removes the recovery code, it returns false if the code is unknown or used.
+func UseRecoveryCode(userId primitive.ObjectID, codeHash string) (bool, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true, "twoFactor.recoveryCodes": codeHash}
+	updateOp := bson.M{"$pull": bson.M{"twoFactor.recoveryCodes": codeHash}}
+	result, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to use recovery code of user(%s): %v\n", userId.Hex(), err)
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func SaveTwoFactorChallenge(challenge *model.TwoFactorChallenge) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	if _, err := GetCollection(TwoFactorChallengeCollectionName).InsertOne(ctx, challenge); err != nil {
+		log.Printf("Failed to save two-factor challenge for user(%s): %v\n", challenge.UserId.Hex(), err)
+		return err
+	}
+	return nil
+}
+
+// ClaimTwoFactorAttempt uses one attempt of the challenge before the code is checked, so
+// concurrent requests cannot try more codes than allowed. It fails with ErrNoDocuments when the
+// challenge expired or ran out of attempts.
+func ClaimTwoFactorAttempt(tokenHash string, now time.Time) (*model.TwoFactorChallenge, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{
+		"_id":       tokenHash,
+		"expiresAt": bson.M{"$gt": now},
+		"attempts":  bson.M{"$lt": model.MaxTwoFactorChallengeAttempts},
+	}
+	updateOp := bson.M{"$inc": bson.M{"attempts": 1}}
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var challenge model.TwoFactorChallenge
+	err := GetCollection(TwoFactorChallengeCollectionName).FindOneAndUpdate(ctx, filter, updateOp, findOptions).Decode(&challenge)
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// RecordTwoFactorFailure counts an invalid code of the user and locks the second factor once
+// MaxTwoFactorFailures are reached. The count survives new challenges and is reset on success.
+func RecordTwoFactorFailure(userId primitive.ObjectID, now time.Time) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	collection := GetCollection(UserCollectionName)
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true}
+	updateOp := bson.M{"$inc": bson.M{"twoFactor.failedAttempts": 1}}
+	if _, err := collection.UpdateOne(ctx, filter, updateOp); err != nil {
+		log.Printf("Failed to record two-factor failure of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	filter = bson.M{"_id": userId, "twoFactor.failedAttempts": bson.M{"$gte": model.MaxTwoFactorFailures}}
+	updateOp = bson.M{"$set": bson.M{
+		"twoFactor.failedAttempts": 0,
+		"twoFactor.lockedUntil":    now.Add(model.TwoFactorLockout),
+	}}
+	result, err := collection.UpdateOne(ctx, filter, updateOp)
+	if err != nil {
+		log.Printf("Failed to lock two-factor authentication of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	if result.ModifiedCount == 1 {
+		log.Printf("Locked two-factor authentication of user(%s) after %d invalid codes\n", userId.Hex(), model.MaxTwoFactorFailures)
+	}
+	return nil
+}
+
+func ResetTwoFactorFailures(userId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true}
+	updateOp := bson.M{
+		"$set":   bson.M{"twoFactor.failedAttempts": 0},
+		"$unset": bson.M{"twoFactor.lockedUntil": ""},
+	}
+	if _, err := GetCollection(UserCollectionName).UpdateOne(ctx, filter, updateOp); err != nil {
+		log.Printf("Failed to reset two-factor failures of user(%s): %v\n", userId.Hex(), err)
+		return err
+	}
+	return nil
+}
+
+// ConsumeTwoFactorChallenge deletes the challenge, so it signs in only once.
+func ConsumeTwoFactorChallenge(tokenHash string) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	return GetCollection(TwoFactorChallengeCollectionName).FindOneAndDelete(ctx, bson.M{"_id": tokenHash}).Err()
+}
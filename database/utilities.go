@@ -11,12 +11,24 @@ import (
 )
 
 const (
-	UserCollectionName            = "users"
-	SessionCollectionName         = "sessions"
-	ReviewCollectionName          = "reviews"
-	AuthSessionCollectionName     = "authSessions"
-	ValuesForSelectCollectionName = "selectValues"
-	FieldInfoCollectionName       = "fieldInfo"
+	UserCollectionName                    = "users"
+	SessionCollectionName                 = "sessions"
+	ReviewCollectionName                  = "reviews"
+	AuthSessionCollectionName             = "authSessions"
+	ValuesForSelectCollectionName         = "selectValues"
+	FieldInfoCollectionName               = "fieldInfo"
+	BookingLockCollectionName             = "bookingLocks"
+	SessionReminderCollectionName         = "sessionReminders"
+	JobLockCollectionName                 = "jobLocks"
+	JobRunCollectionName                  = "jobRuns"
+	EmailOutboxCollectionName             = "emailOutbox"
+	NotificationPreferencesCollectionName = "notificationPreferences"
+	NotificationCollectionName            = "notifications"
+	WebhookSubscriptionCollectionName     = "webhookSubscriptions"
+	WebhookDeliveryCollectionName         = "webhookDeliveries"
+	EmailEventCollectionName              = "emailEvents"
+	PasswordResetTokenCollectionName      = "passwordResetTokens"
+	TwoFactorChallengeCollectionName      = "twoFactorChallenges"
 )
 
 // todo get from database
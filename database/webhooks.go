@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"oysterProject/model"
+	"time"
+)
+
+const (
+	webhookClaimTimeout     = 5 * time.Minute
+	maxWebhookResponseBytes = 1024
+)
+
+func CreateWebhookSubscription(subscription *model.WebhookSubscription) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	subscription.CreatedAt = time.Now().UTC()
+	result, err := GetCollection(WebhookSubscriptionCollectionName).InsertOne(ctx, subscription)
+	if err != nil {
+		log.Printf("CreateWebhookSubscription: failed to insert subscription: %v\n", err)
+		return err
+	}
+	subscription.Id = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func GetWebhookSubscriptions() ([]*model.WebhookSubscription, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	return findWebhookSubscriptions(ctx, bson.M{})
+}
+
+// GetWebhookSubscriptionsForEvent returns the active subscriptions listening to the event.
+func GetWebhookSubscriptionsForEvent(ctx context.Context, event string) ([]*model.WebhookSubscription, error) {
+	return findWebhookSubscriptions(ctx, bson.M{"active": true, "events": event})
+}
+
+func findWebhookSubscriptions(ctx context.Context, filter bson.M) ([]*model.WebhookSubscription, error) {
+	findOptions := options.Find().SetSort(bson.D{{"createdAt", 1}})
+	cursor, err := GetCollection(WebhookSubscriptionCollectionName).Find(ctx, filter, findOptions)
+	if err != nil {
+		log.Printf("Failed to find webhook subscriptions: %v\n", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	subscriptions := []*model.WebhookSubscription{}
+	if err = cursor.All(ctx, &subscriptions); err != nil {
+		log.Printf("Failed to decode webhook subscriptions: %v\n", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func GetWebhookSubscription(ctx context.Context, subscriptionId primitive.ObjectID) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	err := GetCollection(WebhookSubscriptionCollectionName).FindOne(ctx, bson.M{"_id": subscriptionId}).Decode(&subscription)
+	if err != nil {
+		handleFindError(err, subscriptionId.Hex(), "webhook subscription")
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// UpdateWebhookSubscription changes everything except the secret.
+func UpdateWebhookSubscription(subscriptionId primitive.ObjectID, subscription *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	setFields := bson.M{
+		"name":      subscription.Name,
+		"url":       subscription.URL,
+		"events":    subscription.Events,
+		"updatedAt": time.Now().UTC(),
+	}
+	// an update without the active field keeps the endpoint in its current state
+	if subscription.Active != nil {
+		setFields["active"] = *subscription.Active
+	}
+	updateOp := bson.M{"$set": setFields}
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updatedSubscription model.WebhookSubscription
+	err := GetCollection(WebhookSubscriptionCollectionName).
+		FindOneAndUpdate(ctx, bson.M{"_id": subscriptionId}, updateOp, findOptions).
+		Decode(&updatedSubscription)
+	if err != nil {
+		handleFindError(err, subscriptionId.Hex(), "webhook subscription")
+		return nil, err
+	}
+	return &updatedSubscription, nil
+}
+
+func DeleteWebhookSubscription(subscriptionId primitive.ObjectID) error {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	result, err := GetCollection(WebhookSubscriptionCollectionName).DeleteOne(ctx, bson.M{"_id": subscriptionId})
+	if err != nil {
+		log.Printf("Failed to delete webhook subscription(%s): %v\n", subscriptionId.Hex(), err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// InsertWebhookDeliveries queues the deliveries. A delivery whose DedupeKey already exists for
+// the subscription is not added again.
+func InsertWebhookDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	var models []mongo.WriteModel
+	for _, delivery := range deliveries {
+		delivery.Status = model.WebhookDeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = now
+		delivery.CreatedAt = now
+		if delivery.DedupeKey == "" {
+			delivery.Id = primitive.NewObjectID()
+			models = append(models, mongo.NewInsertOneModel().SetDocument(delivery))
+			continue
+		}
+		filter := bson.M{"subscriptionId": delivery.SubscriptionId, "dedupeKey": delivery.DedupeKey}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$setOnInsert": delivery}).SetUpsert(true))
+	}
+	_, err := GetCollection(WebhookDeliveryCollectionName).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		log.Printf("InsertWebhookDeliveries: failed to insert deliveries: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// ClaimDueWebhookDelivery atomically hands one due delivery to the owner. Deliveries whose
+// claim expired because the owner stopped are claimed again.
+func ClaimDueWebhookDelivery(ctx context.Context, owner string, now time.Time) (*model.WebhookDelivery, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{model.WebhookDeliveryPending, model.WebhookDeliveryFailed}}, "nextAttemptAt": bson.M{"$lte": now}},
+			bson.M{"status": model.WebhookDeliverySending, "claimedUntil": bson.M{"$lt": now}},
+		},
+	}
+	updateOp := bson.M{
+		"$set": bson.M{
+			"status":       model.WebhookDeliverySending,
+			"claimedBy":    owner,
+			"claimedUntil": now.Add(webhookClaimTimeout),
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{"nextAttemptAt", 1}}).
+		SetReturnDocument(options.After)
+	var delivery model.WebhookDelivery
+	err := GetCollection(WebhookDeliveryCollectionName).FindOneAndUpdate(ctx, filter, updateOp, findOptions).Decode(&delivery)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("ClaimDueWebhookDelivery: failed to claim delivery: %v\n", err)
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// CompleteWebhookDelivery stores the response of the endpoint. A failed delivery is retried
+// with exponential backoff until it runs out of attempts and becomes dead.
+func CompleteWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery, responseStatus int, responseBody string, sendErr error) (model.WebhookDeliveryStatus, error) {
+	filter := bson.M{"_id": delivery.Id, "claimedBy": delivery.ClaimedBy, "status": model.WebhookDeliverySending}
+	now := time.Now().UTC()
+	if len(responseBody) > maxWebhookResponseBytes {
+		responseBody = responseBody[:maxWebhookResponseBytes]
+	}
+	setFields := bson.M{"responseStatus": responseStatus, "responseBody": responseBody}
+	switch {
+	case sendErr == nil:
+		setFields["status"] = model.WebhookDeliverySucceeded
+		setFields["deliveredAt"] = now
+	case delivery.Attempts >= model.MaxWebhookAttempts:
+		setFields["status"] = model.WebhookDeliveryDead
+		setFields["lastError"] = sendErr.Error()
+	default:
+		setFields["status"] = model.WebhookDeliveryFailed
+		setFields["lastError"] = sendErr.Error()
+		setFields["nextAttemptAt"] = now.Add(model.GetRetryDelay(delivery.Attempts))
+	}
+	status := setFields["status"].(model.WebhookDeliveryStatus)
+	updateOp := bson.M{"$set": setFields, "$unset": bson.M{"claimedUntil": ""}}
+	if _, err := GetCollection(WebhookDeliveryCollectionName).UpdateOne(ctx, filter, updateOp); err != nil {
+		log.Printf("CompleteWebhookDelivery: failed to update delivery(%s): %v\n", delivery.Id.Hex(), err)
+		return status, err
+	}
+	return status, nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries of the subscription, newest first.
+func GetWebhookDeliveries(subscriptionId primitive.ObjectID, limit int64) ([]*model.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	findOptions := options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(limit)
+	cursor, err := GetCollection(WebhookDeliveryCollectionName).Find(ctx, bson.M{"subscriptionId": subscriptionId}, findOptions)
+	if err != nil {
+		log.Printf("Failed to find deliveries of webhook(%s): %v\n", subscriptionId.Hex(), err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	deliveries := []*model.WebhookDelivery{}
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		log.Printf("Failed to decode deliveries of webhook(%s): %v\n", subscriptionId.Hex(), err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ReplayWebhookDelivery queues the payload of the delivery again as a new delivery.
+func ReplayWebhookDelivery(deliveryId primitive.ObjectID) (*model.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	var original model.WebhookDelivery
+	err := GetCollection(WebhookDeliveryCollectionName).FindOne(ctx, bson.M{"_id": deliveryId}).Decode(&original)
+	if err != nil {
+		handleFindError(err, deliveryId.Hex(), "webhook delivery")
+		return nil, err
+	}
+	replay := &model.WebhookDelivery{
+		SubscriptionId: original.SubscriptionId,
+		EventId:        original.EventId,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		ReplayOf:       &original.Id,
+	}
+	if err = InsertWebhookDeliveries(ctx, []*model.WebhookDelivery{replay}); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
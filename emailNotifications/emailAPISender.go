@@ -1,81 +1,92 @@
 package emailNotifications
 
 import (
-	"github.com/sendgrid/sendgrid-go"
-	"github.com/sendgrid/sendgrid-go/helpers/mail"
-	"go.mongodb.org/mongo-driver/bson"
 	"log"
-	"os"
+	"oysterProject/calendar"
 	"oysterProject/database"
 	"oysterProject/model"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	mentorFilledQuestionsTemplateID        = "d-acb26f6c4b9a41309e281021ebdafe77"
-	menteeFilledQuestionsTemplateID        = "d-be156416c7874548a5d40ab22c6c448f"
-	mentorSessionCreatedTemplateID         = "d-e67f8b8a8373472089bfe585a2119388"
-	menteeSessionCreatedFreeTemplateID     = "d-747f16f016924f30ae6fa431df681ad2"
-	menteeSessionCreatedDonationTemplateID = "d-ce0dc32b002c446d9ef1e9e057bf4e31"
-	menteeSessionCreatedPaidTemplateID     = "d-13aec49e40c6406da7c73591c3423806"
-	menteeSessionConfirmedTemplateID       = "d-ae48b32f57c34243a9e6dfcc9f08dbba"
-	mentorSessionConfirmedTemplateID       = "d-734268aa40dd4916bde04cbde8e63bc9"
-	menteeSessionRescheduledTemplateID     = "d-ab0511265c0646d58acc823ba92a3376"
-	mentorSessionRescheduledTemplateID     = "d-e1bf34de616946f1afc738bd898901b0"
-	sessionMenteeNotificationTemplateID    = "d-cee7fe5b36084acc8274d586384d90a2"
-	sessionMentorNotificationTemplateID    = "d-3a115ebf9fe94d57bc165a70669e0bde"
-	reviewMenteeEmailTemplateID            = "d-168655d6a3ce4462ba2f5a2f00b71b0f"
-	reviewMentorEmailTemplateID            = "d-05dd4350b278413e915e892298ccd6f1"
-	mentorApprovedEmailTemplateID          = "d-06042ffe71e14c6fb68b7784fe6a8c01"
+	mentorFilledQuestionsTemplate        = "filledQuestionsMentor"
+	menteeFilledQuestionsTemplate        = "filledQuestionsMentee"
+	mentorSessionCreatedTemplate         = "sessionCreatedMentor"
+	menteeSessionCreatedFreeTemplate     = "sessionCreatedMenteeFree"
+	menteeSessionCreatedDonationTemplate = "sessionCreatedMenteeDonation"
+	menteeSessionCreatedPaidTemplate     = "sessionCreatedMenteePaid"
+	menteeSessionConfirmedTemplate       = "sessionConfirmedMentee"
+	mentorSessionConfirmedTemplate       = "sessionConfirmedMentor"
+	menteeSessionRescheduledTemplate     = "sessionRescheduledMentee"
+	mentorSessionRescheduledTemplate     = "sessionRescheduledMentor"
+	sessionMenteeNotificationTemplate    = "sessionReminderMentee"
+	sessionMentorNotificationTemplate    = "sessionReminderMentor"
+	reviewMenteeEmailTemplate            = "reviewRequestMentee"
+	reviewMentorEmailTemplate            = "reviewRequestMentor"
+	mentorApprovedEmailTemplate          = "mentorApproved"
+	sessionCanceledTemplate              = "sessionCanceled"
+	sessionCanceledByYouTemplate         = "sessionCanceledByYou"
+	passwordResetTemplate                = "passwordReset"
+	verifyEmailTemplate                  = "verifyEmail"
 )
 
-var (
-	client    *sendgrid.Client
-	emailFrom = mail.NewEmail("Oyster", "info@oystermentors.com")
+const (
+	emailFromName    = "Oyster"
+	emailFromAddress = "info@oystermentors.com"
 )
 
-func InitMailClient() {
-	client = sendgrid.NewSendClient(os.Getenv("SEND_GRID_KEY"))
+// durationUnits holds the words for "hour", "hours" and "minutes" used in reminders.
+var durationUnits = map[string][3]string{
+	"en": {"hour", "hours", "minutes"},
+	"es": {"hora", "horas", "minutos"},
 }
 
-func sendEmailMessage(message *mail.SGMailV3) {
-	response, err := client.Send(message)
-	if err != nil {
-		log.Println("Failed to send email:", err)
-		return
+func sendEmailMessage(message *Message) error {
+	message.FromName, message.FromEmail = emailFromName, emailFromAddress
+	if err := mailer.Send(message); err != nil {
+		log.Printf("Failed to send email to %s: %v\n", message.ToEmail, err)
+		return err
 	}
+	log.Println("Email sent successfully")
+	return nil
+}
 
-	if response.StatusCode >= 200 && response.StatusCode < 300 {
-		log.Println("Email sent successfully")
-	} else {
-		log.Println("Failed to send email. Status:", response.StatusCode)
+// sendTemplateEmail queues the email unless the recipient opted out of the category, the
+// outbox worker delivers it.
+func sendTemplateEmail(templateName string, category model.NotificationCategory, to recipient, dynamicTemplateData map[string]any, attachments ...*Attachment) {
+	if !isEmailWanted(to, category) {
+		return
 	}
+	email, err := newTemplateEmail(templateName, category, to, dynamicTemplateData, attachments...)
+	if err != nil {
+		return
+	}
+	enqueueEmail(email)
 }
 
-func sendTemplateEmail(templateID, toName, toEmail string, dynamicTemplateData map[string]any) {
-	message := mail.NewV3Mail()
-	personalization := mail.NewPersonalization()
-	personalization.DynamicTemplateData = dynamicTemplateData
-	personalization.AddTos(mail.NewEmail(toName, toEmail))
-	personalization.SetHeader("Importance", "high")
-
-	message.SetTemplateID(templateID)
-	message.AddPersonalizations(personalization)
-	message.SetFrom(emailFrom)
-
-	sendEmailMessage(message)
+// newCalendarAttachment attaches the session as an iTIP message, so calendar clients add,
+// update or remove the event with the session UID.
+func newCalendarAttachment(session *model.SessionResponse, method string) *Attachment {
+	invite := calendar.NewSessionInvite(session, method)
+	return &Attachment{
+		Filename:    "invite.ics",
+		ContentType: "text/calendar; charset=UTF-8; method=" + method,
+		Content:     invite.Bytes(),
+	}
 }
 
 func SendUserFilledQuestionsEmail(user *model.User) {
-	templateID := menteeFilledQuestionsTemplateID
+	templateName := menteeFilledQuestionsTemplate
 	if user.AsMentor {
-		templateID = mentorFilledQuestionsTemplateID
+		templateName = mentorFilledQuestionsTemplate
 	}
 
 	dynamicTemplateData := map[string]any{
 		"name": user.Username,
 	}
-	sendTemplateEmail(templateID, user.Username, user.Email, dynamicTemplateData)
+	sendTemplateEmail(templateName, model.CategoryOnboarding, userRecipient(user), dynamicTemplateData)
 }
 
 func SendSessionWasCreatedEmail(session *model.SessionResponse) {
@@ -84,22 +95,22 @@ func SendSessionWasCreatedEmail(session *model.SessionResponse) {
 		"menteeName": session.Mentee.Name,
 		"price":      session.PaymentDetails,
 	}
-	sessionDate, sessionTime := model.GetSessionTime(session)
+	sessionDate, sessionTime := model.GetSessionTime(session, session.Mentor.TimeZone)
 	dynamicTemplateData["sessionDate"] = sessionDate
 	dynamicTemplateData["sessionTime"] = sessionTime
-	sendTemplateEmail(mentorSessionCreatedTemplateID, session.Mentor.Name, session.Mentor.Email, dynamicTemplateData)
-	var templateId string
+	sendTemplateEmail(mentorSessionCreatedTemplate, model.CategorySessionUpdates, userImageRecipient(session.Mentor), dynamicTemplateData)
+	var templateName string
 	if strings.EqualFold(session.PaymentDetails, "free") {
-		templateId = menteeSessionCreatedFreeTemplateID
+		templateName = menteeSessionCreatedFreeTemplate
 	} else if strings.EqualFold(session.PaymentDetails, "donation") {
-		templateId = menteeSessionCreatedDonationTemplateID
+		templateName = menteeSessionCreatedDonationTemplate
 	} else {
-		templateId = menteeSessionCreatedPaidTemplateID
+		templateName = menteeSessionCreatedPaidTemplate
 	}
-	sessionDate, sessionTime = model.GetSessionTime(session)
+	sessionDate, sessionTime = model.GetSessionTime(session, session.Mentee.TimeZone)
 	dynamicTemplateData["sessionDate"] = sessionDate
 	dynamicTemplateData["sessionTime"] = sessionTime
-	sendTemplateEmail(templateId, session.Mentee.Name, session.Mentee.Email, dynamicTemplateData)
+	sendTemplateEmail(templateName, model.CategorySessionUpdates, userImageRecipient(session.Mentee), dynamicTemplateData)
 }
 
 func SendSessionConfirmedEmail(session *model.SessionResponse) {
@@ -107,15 +118,16 @@ func SendSessionConfirmedEmail(session *model.SessionResponse) {
 		"mentorName": session.Mentor.Name,
 		"menteeName": session.Mentee.Name,
 	}
-	sessionDate, sessionTime := model.GetSessionTime(session)
+	sessionDate, sessionTime := model.GetSessionTime(session, session.Mentor.TimeZone)
 	dynamicTemplateData["sessionDate"] = sessionDate
 	dynamicTemplateData["sessionTime"] = sessionTime
-	sendTemplateEmail(mentorSessionConfirmedTemplateID, session.Mentor.Name, session.Mentor.Email, dynamicTemplateData)
+	invite := newCalendarAttachment(session, calendar.MethodRequest)
+	sendTemplateEmail(mentorSessionConfirmedTemplate, model.CategorySessionUpdates, userImageRecipient(session.Mentor), dynamicTemplateData, invite)
 
-	sessionDate, sessionTime = model.GetSessionTime(session)
+	sessionDate, sessionTime = model.GetSessionTime(session, session.Mentee.TimeZone)
 	dynamicTemplateData["sessionDate"] = sessionDate
 	dynamicTemplateData["sessionTime"] = sessionTime
-	sendTemplateEmail(menteeSessionConfirmedTemplateID, session.Mentee.Name, session.Mentee.Email, dynamicTemplateData)
+	sendTemplateEmail(menteeSessionConfirmedTemplate, model.CategorySessionUpdates, userImageRecipient(session.Mentee), dynamicTemplateData, invite)
 }
 
 func SendSessionRescheduledEmail(session *model.SessionResponse) {
@@ -124,29 +136,58 @@ func SendSessionRescheduledEmail(session *model.SessionResponse) {
 		"menteeName": session.Mentee.Name,
 	}
 
-	templateID := mentorSessionRescheduledTemplateID
-	toName := session.Mentor.Name
-	toEmail := session.Mentor.Email
+	templateName := mentorSessionRescheduledTemplate
+	receiver := session.Mentor
 
 	if session.SessionStatus == model.ReschedulingByMentee {
-		templateID = menteeSessionRescheduledTemplateID
-		toName = session.Mentee.Name
-		toEmail = session.Mentee.Email
-		sessionDate, sessionTime := model.GetSessionTime(session)
-		dynamicTemplateData["sessionDate"] = sessionDate
-		dynamicTemplateData["sessionTime"] = sessionTime
-	} else if session.SessionStatus == model.ReschedulingByMentor {
-		sessionDate, sessionTime := model.GetSessionTime(session)
-		dynamicTemplateData["sessionDate"] = sessionDate
-		dynamicTemplateData["sessionTime"] = sessionTime
-	} else {
+		templateName = menteeSessionRescheduledTemplate
+		receiver = session.Mentee
+	} else if session.SessionStatus != model.ReschedulingByMentor {
 		log.Printf("Wrong session status to send rescheduled email. Session id:%s, status:%s", session.SessionId, session.SessionStatus)
 		return
 	}
-	sendTemplateEmail(templateID, toName, toEmail, dynamicTemplateData)
+	sessionDate, sessionTime := model.GetSessionTime(session, receiver.TimeZone)
+	dynamicTemplateData["sessionDate"] = sessionDate
+	dynamicTemplateData["sessionTime"] = sessionTime
+	// the calendars are updated by SendSessionConfirmedEmail once the new time is accepted
+	sendTemplateEmail(templateName, model.CategorySessionUpdates, userImageRecipient(receiver), dynamicTemplateData)
+}
+
+// SendSessionCanceledEmail tells the counterpart who canceled the session and why, and
+// removes the session from the calendars of both participants.
+func SendSessionCanceledEmail(session *model.SessionResponse) {
+	canceledBy, counterpart := session.Mentee, session.Mentor
+	switch session.SessionStatus {
+	case model.CanceledByMentor:
+		canceledBy, counterpart = session.Mentor, session.Mentee
+	case model.CanceledByMentee:
+	default:
+		log.Printf("Wrong session status to send canceled email. Session id:%s, status:%s", session.SessionId, session.SessionStatus)
+		return
+	}
+	cancellation := newCalendarAttachment(session, calendar.MethodCancel)
+
+	sessionDate, sessionTime := model.GetSessionTime(session, counterpart.TimeZone)
+	dynamicTemplateData := map[string]any{
+		"recipientName":  counterpart.Name,
+		"canceledByName": canceledBy.Name,
+		"reason":         session.CancellationReason,
+		"sessionDate":    sessionDate,
+		"sessionTime":    sessionTime,
+	}
+	sendTemplateEmail(sessionCanceledTemplate, model.CategorySessionUpdates, userImageRecipient(counterpart), dynamicTemplateData, cancellation)
+
+	sessionDate, sessionTime = model.GetSessionTime(session, canceledBy.TimeZone)
+	dynamicTemplateData = map[string]any{
+		"recipientName":   canceledBy.Name,
+		"counterpartName": counterpart.Name,
+		"sessionDate":     sessionDate,
+		"sessionTime":     sessionTime,
+	}
+	sendTemplateEmail(sessionCanceledByYouTemplate, model.CategorySessionUpdates, userImageRecipient(canceledBy), dynamicTemplateData, cancellation)
 }
 
-func SendNotificationBeforeSession(session *model.SessionNotification) {
+func SendNotificationBeforeSession(session *model.SessionNotification, timeBeforeSession time.Duration) {
 	dynamicTemplateData := map[string]any{
 		"mentorName":     session.MentorName,
 		"menteeName":     session.MenteeName,
@@ -154,38 +195,96 @@ func SendNotificationBeforeSession(session *model.SessionNotification) {
 		"paymentDetails": session.PaymentDetails,
 	}
 
-	sendTemplateEmail(sessionMenteeNotificationTemplateID, session.MenteeName, session.MenteeEmail, dynamicTemplateData)
-	sendTemplateEmail(sessionMentorNotificationTemplateID, session.MentorName, session.MentorEmail, dynamicTemplateData)
+	dynamicTemplateData["timeBeforeSession"] = formatTimeBeforeSession(timeBeforeSession, session.MenteeLanguage)
+	sendTemplateEmail(sessionMenteeNotificationTemplate, model.CategoryReminders, menteeRecipient(session), dynamicTemplateData)
+	dynamicTemplateData["timeBeforeSession"] = formatTimeBeforeSession(timeBeforeSession, session.MentorLanguage)
+	sendTemplateEmail(sessionMentorNotificationTemplate, model.CategoryReminders, mentorRecipient(session), dynamicTemplateData)
 }
 
+func formatTimeBeforeSession(timeBeforeSession time.Duration, language string) string {
+	units, ok := durationUnits[language]
+	if !ok {
+		units = durationUnits[DefaultLanguage]
+	}
+	if timeBeforeSession == time.Hour {
+		return "1 " + units[0]
+	}
+	if timeBeforeSession >= time.Hour && timeBeforeSession%time.Hour == 0 {
+		return strconv.Itoa(int(timeBeforeSession/time.Hour)) + " " + units[1]
+	}
+	return strconv.Itoa(int(timeBeforeSession/time.Minute)) + " " + units[2]
+}
+
+// SendReviewEmails queues the review requests once per session. emailWasSent is set when every
+// queued email is delivered, until then the session is picked up again and the enqueue is a no-op.
 func SendReviewEmails(session *model.SessionNotification) {
 	dynamicTemplateData := map[string]any{
 		"mentorName": session.MentorName,
 		"menteeName": session.MenteeName,
-		"sessionId":  session.SessionId,
+		"sessionId":  session.SessionId.Hex(),
 	}
+	group := "reviewRequest:" + session.SessionId.Hex()
+	onSent := &model.EmailSentUpdate{
+		Collection: database.SessionCollectionName,
+		DocumentId: session.SessionId,
+		Field:      "emailWasSent",
+	}
+	recipients := map[string]recipient{"mentee": menteeRecipient(session), "mentor": mentorRecipient(session)}
+	templates := map[string]string{"mentee": reviewMenteeEmailTemplate, "mentor": reviewMentorEmailTemplate}
 
-	sendTemplateEmail(reviewMenteeEmailTemplateID, session.MenteeName, session.MenteeEmail, dynamicTemplateData)
-	sendTemplateEmail(reviewMentorEmailTemplateID, session.MentorName, session.MentorEmail, dynamicTemplateData)
-
-	filter := bson.M{"_id": session.SessionId}
-	updateOp := bson.M{
-		"$set": bson.M{
-			"emailWasSent": true,
-		},
+	var emails []*model.OutboxEmail
+	for role, to := range recipients {
+		if !isEmailWanted(to, model.CategoryReviewRequests) {
+			continue
+		}
+		email, err := newTemplateEmail(templates[role], model.CategoryReviewRequests, to, dynamicTemplateData)
+		if err != nil {
+			return
+		}
+		email.DedupeKey = group + ":" + role
+		email.Group = group
+		email.OnSent = onSent
+		emails = append(emails, email)
 	}
-	if _, err := database.UpdateSession(filter, updateOp); err != nil {
-		log.Printf("SendReviewEmails: Failed to update session(%s) err: %v\n", filter["_id"], err)
+	if len(emails) == 0 {
+		_ = database.SetEmailSentFlag(onSent)
+		return
+	}
+	for _, email := range emails {
+		enqueueEmail(email)
 	}
 }
 
+// SendApprovedEmail queues the email once per user, ApprovedEmailWasSent is set on delivery.
 func SendApprovedEmail(user *model.User) {
 	dynamicTemplateData := map[string]any{
 		"mentorName": user.Username,
 	}
-	sendTemplateEmail(mentorApprovedEmailTemplateID, user.Username, user.Email, dynamicTemplateData)
-	user.ApprovedEmailWasSent = true //todo create plain update user
-	if _, err := database.UpdateAndGetUser(user, user.Id); err != nil {
-		log.Printf("SendApprovedEmail: Failed to update user(%s) err: %v\n", user.Id.Hex(), err)
+	onSent := &model.EmailSentUpdate{
+		Collection: database.UserCollectionName,
+		DocumentId: user.Id,
+		Field:      "approvedEmailWasSent",
+	}
+	to := userRecipient(user)
+	if !isEmailWanted(to, model.CategoryOnboarding) {
+		_ = database.SetEmailSentFlag(onSent)
+		return
+	}
+	email, err := newTemplateEmail(mentorApprovedEmailTemplate, model.CategoryOnboarding, to, dynamicTemplateData)
+	if err != nil {
+		return
+	}
+	email.DedupeKey = "mentorApproved:" + user.Id.Hex()
+	email.OnSent = onSent
+	enqueueEmail(email)
+}
+
+// SendPasswordResetEmail sends the reset link regardless of the notification preferences.
+func SendPasswordResetEmail(user *model.User, token string) {
+	dynamicTemplateData := map[string]any{
+		"name":      user.Username,
+		"token":     token,
+		"expiresIn": formatTimeBeforeSession(model.PasswordResetTokenTTL, user.PreferredLanguage),
 	}
+	sendTemplateEmail(passwordResetTemplate, model.CategoryAccount, userRecipient(user), dynamicTemplateData)
 }
@@ -0,0 +1,214 @@
+package emailNotifications
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"oysterProject/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useMemoryMailer delivers every queued email to the returned mailer right away.
+func useMemoryMailer(t *testing.T) *MemoryMailer {
+	t.Helper()
+	t.Setenv("FRONTEND_URL", "https://app.example.com")
+	t.Setenv("ENV_URL", "https://api.example.com")
+	t.Setenv("LINK_SIGNING_SECRET", "test-secret")
+	previousMailer, previousQueueEmail, previousIsEmailWanted := mailer, queueEmail, isEmailWanted
+	t.Cleanup(func() {
+		mailer, queueEmail, isEmailWanted = previousMailer, previousQueueEmail, previousIsEmailWanted
+	})
+	memoryMailer := NewMemoryMailer()
+	SetMailer(memoryMailer)
+	queueEmail = func(email *model.OutboxEmail) error {
+		email.Id = primitive.NewObjectID()
+		return DeliverEmail(email)
+	}
+	isEmailWanted = func(recipient, model.NotificationCategory) bool {
+		return true
+	}
+	return memoryMailer
+}
+
+func newTestSession(status model.Status) *model.SessionResponse {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	return &model.SessionResponse{
+		SessionId:        primitive.NewObjectID(),
+		Mentor:           &model.UserImage{UserId: primitive.NewObjectID(), Name: "Maria", Email: "maria@example.com", TimeZone: "Europe/Madrid"},
+		Mentee:           &model.UserImage{UserId: primitive.NewObjectID(), Name: "John", Email: "john@example.com", TimeZone: "America/New_York", PreferredLanguage: "es"},
+		SessionTimeStart: &start,
+		SessionTimeEnd:   &end,
+		SessionStatus:    status,
+		PaymentDetails:   "paid",
+		MeetingLink:      "https://meet.example.com/abc",
+	}
+}
+
+type wantMessage struct {
+	to       string
+	template string
+	// inviteMethod is the iTIP method of the calendar attachment, empty when there is none
+	inviteMethod string
+	contains     string
+}
+
+func TestSendEmails(t *testing.T) {
+	user := &model.User{Id: primitive.NewObjectID(), Username: "John", Email: "john@example.com"}
+	tests := []struct {
+		name string
+		send func(session *model.SessionResponse)
+		// status of the session passed to send
+		status model.Status
+		want   []wantMessage
+	}{
+		{
+			name:   "session created",
+			send:   SendSessionWasCreatedEmail,
+			status: model.PendingByMentor,
+			want: []wantMessage{
+				{to: "maria@example.com", template: mentorSessionCreatedTemplate},
+				{to: "john@example.com", template: menteeSessionCreatedPaidTemplate},
+			},
+		},
+		{
+			name:   "session confirmed",
+			send:   SendSessionConfirmedEmail,
+			status: model.Confirmed,
+			want: []wantMessage{
+				{to: "maria@example.com", template: mentorSessionConfirmedTemplate, inviteMethod: "REQUEST"},
+				{to: "john@example.com", template: menteeSessionConfirmedTemplate, inviteMethod: "REQUEST"},
+			},
+		},
+		{
+			name:   "session rescheduled by mentee",
+			send:   SendSessionRescheduledEmail,
+			status: model.ReschedulingByMentee,
+			want:   []wantMessage{{to: "john@example.com", template: menteeSessionRescheduledTemplate}},
+		},
+		{
+			name:   "session rescheduled by mentor",
+			send:   SendSessionRescheduledEmail,
+			status: model.ReschedulingByMentor,
+			want:   []wantMessage{{to: "maria@example.com", template: mentorSessionRescheduledTemplate}},
+		},
+		{
+			name:   "session canceled by mentor",
+			send:   SendSessionCanceledEmail,
+			status: model.CanceledByMentor,
+			want: []wantMessage{
+				{to: "john@example.com", template: sessionCanceledTemplate, inviteMethod: "CANCEL", contains: "Maria"},
+				{to: "maria@example.com", template: sessionCanceledByYouTemplate, inviteMethod: "CANCEL", contains: "John"},
+			},
+		},
+		{
+			name:   "session canceled by mentee",
+			send:   SendSessionCanceledEmail,
+			status: model.CanceledByMentee,
+			want: []wantMessage{
+				{to: "maria@example.com", template: sessionCanceledTemplate, inviteMethod: "CANCEL", contains: "John"},
+				{to: "john@example.com", template: sessionCanceledByYouTemplate, inviteMethod: "CANCEL", contains: "Maria"},
+			},
+		},
+		{
+			name:   "password reset",
+			send:   func(*model.SessionResponse) { SendPasswordResetEmail(user, "reset-token") },
+			status: model.Confirmed,
+			want: []wantMessage{
+				{to: "john@example.com", template: passwordResetTemplate, contains: "https://app.example.com/resetPassword?token=reset-token"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memoryMailer := useMemoryMailer(t)
+			session := newTestSession(test.status)
+			test.send(session)
+
+			messages := memoryMailer.Messages()
+			if len(messages) != len(test.want) {
+				t.Fatalf("sent %d messages, want %d", len(messages), len(test.want))
+			}
+			for i, want := range test.want {
+				message := messages[i]
+				if message.ToEmail != want.to || message.Template != want.template {
+					t.Errorf("message %d sent %s to %s, want %s to %s", i, message.Template, message.ToEmail, want.template, want.to)
+				}
+				if message.FromEmail != emailFromAddress {
+					t.Errorf("message %d is from %s, want %s", i, message.FromEmail, emailFromAddress)
+				}
+				if strings.TrimSpace(message.Subject) == "" || strings.TrimSpace(message.Text) == "" || strings.TrimSpace(message.HTML) == "" {
+					t.Errorf("message %d has an empty subject or body", i)
+				}
+				if want.contains != "" && !strings.Contains(message.Text, want.contains) {
+					t.Errorf("message %d text does not contain %q:\n%s", i, want.contains, message.Text)
+				}
+				checkInvite(t, message, session, want.inviteMethod)
+			}
+		})
+	}
+}
+
+func checkInvite(t *testing.T, message *Message, session *model.SessionResponse, method string) {
+	t.Helper()
+	if method == "" {
+		if len(message.Attachments) != 0 {
+			t.Errorf("%s has %d attachments, want none", message.Template, len(message.Attachments))
+		}
+		return
+	}
+	if len(message.Attachments) != 1 {
+		t.Fatalf("%s has %d attachments, want the calendar invite", message.Template, len(message.Attachments))
+	}
+	invite := message.Attachments[0]
+	if invite.Filename != "invite.ics" || !strings.HasSuffix(invite.ContentType, "method="+method) {
+		t.Errorf("%s attachment is %s (%s), want invite.ics with method=%s", message.Template, invite.Filename, invite.ContentType, method)
+	}
+	content := string(invite.Content)
+	for _, line := range []string{
+		"METHOD:" + method,
+		"UID:" + session.SessionId.Hex() + "@oystermentors.com",
+		"DTSTART:20240301T100000Z",
+		"DTEND:20240301T110000Z",
+		"mailto:" + session.Mentor.Email,
+		"mailto:" + session.Mentee.Email,
+	} {
+		if !strings.Contains(content, line) {
+			t.Errorf("%s invite does not contain %q:\n%s", message.Template, line, content)
+		}
+	}
+}
+
+// The invite carries the booked time until the new time is confirmed.
+func TestSendSessionRescheduledEmailWithoutInvite(t *testing.T) {
+	memoryMailer := useMemoryMailer(t)
+	session := newTestSession(model.ReschedulingByMentee)
+	newStart := session.SessionTimeStart.Add(24 * time.Hour)
+	newEnd := newStart.Add(time.Hour)
+	session.NewSessionTimeStart, session.NewSessionTimeEnd = &newStart, &newEnd
+
+	SendSessionRescheduledEmail(session)
+	for _, message := range memoryMailer.Messages() {
+		if len(message.Attachments) != 0 {
+			t.Errorf("%s to %s has a calendar invite before the new time is confirmed", message.Template, message.ToEmail)
+		}
+	}
+}
+
+func TestSendAccountEmailWithoutUnsubscribe(t *testing.T) {
+	memoryMailer := useMemoryMailer(t)
+	user := &model.User{Id: primitive.NewObjectID(), Username: "John", Email: "john@example.com"}
+	SendPasswordResetEmail(user, "reset-token")
+	SendUserFilledQuestionsEmail(user)
+
+	messages := memoryMailer.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(messages))
+	}
+	if _, ok := messages[0].Headers["List-Unsubscribe"]; ok {
+		t.Errorf("%s has an unsubscribe link", messages[0].Template)
+	}
+	if _, ok := messages[1].Headers["List-Unsubscribe"]; !ok {
+		t.Errorf("%s has no unsubscribe link", messages[1].Template)
+	}
+}
@@ -0,0 +1,59 @@
+package emailNotifications
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/url"
+	"os"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	verifyEmailTokenPrefix = "verifyEmail"
+	EmailVerificationTTL   = 24 * time.Hour
+)
+
+// NewEmailVerificationToken signs the user and the address, so a link sent to a previous
+// address cannot verify the current one.
+func NewEmailVerificationToken(userId primitive.ObjectID, email string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return utils.SignToken(strings.Join([]string{verifyEmailTokenPrefix, userId.Hex(), expiry, email}, ":"))
+}
+
+func ParseEmailVerificationToken(token string, now time.Time) (primitive.ObjectID, string, error) {
+	payload, err := utils.VerifySignedToken(token)
+	if err != nil {
+		return primitive.NilObjectID, "", err
+	}
+	parts := strings.SplitN(payload, ":", 4)
+	if len(parts) != 4 || parts[0] != verifyEmailTokenPrefix {
+		return primitive.NilObjectID, "", utils.InvalidSignedToken
+	}
+	userId, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return primitive.NilObjectID, "", utils.InvalidSignedToken
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > expiry {
+		return primitive.NilObjectID, "", utils.InvalidSignedToken
+	}
+	return userId, parts[3], nil
+}
+
+func getEmailVerificationURL(user *model.User) string {
+	token := NewEmailVerificationToken(user.Id, user.Email, time.Now().Add(EmailVerificationTTL))
+	return os.Getenv("FRONTEND_URL") + "/verifyEmail?token=" + url.QueryEscape(token)
+}
+
+// SendVerificationEmail sends the verification link regardless of the notification preferences.
+func SendVerificationEmail(user *model.User) {
+	dynamicTemplateData := map[string]any{
+		"name":      user.Username,
+		"verifyUrl": getEmailVerificationURL(user),
+		"expiresIn": formatTimeBeforeSession(EmailVerificationTTL, user.PreferredLanguage),
+	}
+	sendTemplateEmail(verifyEmailTemplate, model.CategoryAccount, userRecipient(user), dynamicTemplateData)
+}
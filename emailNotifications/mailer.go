@@ -0,0 +1,62 @@
+package emailNotifications
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	mailProviderSendGrid = "sendgrid"
+	mailProviderSMTP     = "smtp"
+	mailProviderMemory   = "memory"
+)
+
+// Message is a provider independent email rendered from one of the embedded templates.
+type Message struct {
+	Id          string
+	FromName    string
+	FromEmail   string
+	ToName      string
+	ToEmail     string
+	Template    string
+	Subject     string
+	Text        string
+	HTML        string
+	Headers     map[string]string
+	Attachments []*Attachment
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+type Mailer interface {
+	Send(message *Message) error
+}
+
+var mailer Mailer
+
+// InitMailClient selects the mailer by MAIL_PROVIDER: sendgrid (default), smtp or memory.
+func InitMailClient() {
+	provider := strings.ToLower(os.Getenv("MAIL_PROVIDER"))
+	switch provider {
+	case mailProviderSMTP:
+		mailer = NewSMTPMailer(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	case mailProviderMemory:
+		mailer = NewMemoryMailer()
+	case "", mailProviderSendGrid:
+		provider = mailProviderSendGrid
+		mailer = NewSendGridMailer(os.Getenv("SEND_GRID_KEY"))
+	default:
+		log.Fatalf("Unknown mail provider: %s\n", provider)
+	}
+	log.Printf("Mail provider: %s\n", provider)
+}
+
+// SetMailer replaces the mailer, e.g. with a MemoryMailer in tests.
+func SetMailer(m Mailer) {
+	mailer = m
+}
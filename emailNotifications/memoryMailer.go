@@ -0,0 +1,32 @@
+package emailNotifications
+
+import "sync"
+
+// MemoryMailer keeps sent messages in memory instead of delivering them.
+type MemoryMailer struct {
+	mutex    sync.Mutex
+	messages []*Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(message *Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *MemoryMailer) Messages() []*Message {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*Message(nil), m.messages...)
+}
+
+func (m *MemoryMailer) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = nil
+}
@@ -0,0 +1,112 @@
+package emailNotifications
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"oysterProject/database"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+type recipient struct {
+	userId   primitive.ObjectID
+	name     string
+	email    string
+	language string
+}
+
+func userRecipient(user *model.User) recipient {
+	return recipient{userId: user.Id, name: user.Username, email: user.Email, language: user.PreferredLanguage}
+}
+
+func userImageRecipient(user *model.UserImage) recipient {
+	return recipient{userId: user.UserId, name: user.Name, email: user.Email, language: user.PreferredLanguage}
+}
+
+func menteeRecipient(session *model.SessionNotification) recipient {
+	return recipient{userId: session.MenteeId, name: session.MenteeName, email: session.MenteeEmail, language: session.MenteeLanguage}
+}
+
+func mentorRecipient(session *model.SessionNotification) recipient {
+	return recipient{userId: session.MentorId, name: session.MentorName, email: session.MentorEmail, language: session.MentorLanguage}
+}
+
+// newTemplateEmail renders the template in the language of the recipient into an outbox email.
+// Emails to users get an unsubscribe link for the category and the RFC 8058 one-click headers.
+func newTemplateEmail(templateName string, category model.NotificationCategory, to recipient, dynamicTemplateData map[string]any, attachments ...*Attachment) (*model.OutboxEmail, error) {
+	templateData := make(map[string]any, len(dynamicTemplateData)+1)
+	for key, value := range dynamicTemplateData {
+		templateData[key] = value
+	}
+	headers := map[string]string{"Importance": "high"}
+	if !to.userId.IsZero() && category != model.CategoryAccount {
+		unsubscribeURL := getUnsubscribeURL(to.userId, category)
+		templateData["unsubscribeUrl"] = unsubscribeURL
+		headers["List-Unsubscribe"] = "<" + unsubscribeURL + ">"
+		headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	}
+	rendered, err := renderTemplate(templateName, to.language, templateData)
+	if err != nil {
+		log.Printf("Failed to render email template %s(%s): %v\n", templateName, to.language, err)
+		return nil, err
+	}
+	email := &model.OutboxEmail{
+		UserId:   to.userId,
+		ToName:   to.name,
+		ToEmail:  to.email,
+		Template: templateName,
+		Category: category,
+		Language: to.language,
+		Subject:  rendered.Subject,
+		Text:     rendered.Text,
+		HTML:     rendered.HTML,
+		Headers:  headers,
+	}
+	if category == model.CategoryAccount {
+		email.ExpiresAt = utils.TimePtr(time.Now().UTC().Add(model.AccountEmailRetention))
+	}
+	for _, attachment := range attachments {
+		email.Attachments = append(email.Attachments, model.EmailAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     attachment.Content,
+		})
+	}
+	return email, nil
+}
+
+// queueEmail and isEmailWanted reach the database. Tests replace them to deliver the emails to a
+// MemoryMailer right away.
+var (
+	queueEmail    = database.EnqueueEmail
+	isEmailWanted = wantsEmail
+)
+
+func enqueueEmail(email *model.OutboxEmail) {
+	if err := queueEmail(email); err != nil {
+		log.Printf("Failed to enqueue email %s to %s: %v\n", email.Template, email.ToEmail, err)
+	}
+}
+
+// DeliverEmail sends an email claimed from the outbox through the configured mailer.
+func DeliverEmail(email *model.OutboxEmail) error {
+	message := &Message{
+		Id:       email.Id.Hex(),
+		ToName:   email.ToName,
+		ToEmail:  email.ToEmail,
+		Template: email.Template,
+		Subject:  email.Subject,
+		Text:     email.Text,
+		HTML:     email.HTML,
+		Headers:  email.Headers,
+	}
+	for _, attachment := range email.Attachments {
+		message.Attachments = append(message.Attachments, &Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     attachment.Content,
+		})
+	}
+	return sendEmailMessage(message)
+}
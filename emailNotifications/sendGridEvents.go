@@ -0,0 +1,86 @@
+package emailNotifications
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"log"
+	"os"
+	"oysterProject/utils"
+	"strconv"
+	"time"
+)
+
+const (
+	SendGridSignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	SendGridTimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+
+	// outboxEmailIdArg is the SendGrid custom argument returned with every event of the email.
+	outboxEmailIdArg = "outboxEmailId"
+	// sendGridTimestampTolerance limits the age of a signed request, so captured requests cannot
+	// be replayed later.
+	sendGridTimestampTolerance = 5 * time.Minute
+)
+
+// VerifySendGridSignature checks the ECDSA signature of the signed event webhook. The signature
+// covers the timestamp header followed by the raw request body, the verification key is set in
+// SENDGRID_WEBHOOK_PUBLIC_KEY as shown in the SendGrid settings. Requests whose timestamp is
+// further than sendGridTimestampTolerance from now are rejected.
+func VerifySendGridSignature(signature, timestamp string, payload []byte) error {
+	if err := checkSendGridTimestamp(timestamp, time.Now()); err != nil {
+		return err
+	}
+	publicKey, err := getSendGridPublicKey()
+	if err != nil {
+		return err
+	}
+	decodedSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return utils.InvalidSignedToken
+	}
+	hash := sha256.New()
+	hash.Write([]byte(timestamp))
+	hash.Write(payload)
+	if !ecdsa.VerifyASN1(publicKey, hash.Sum(nil), decodedSignature) {
+		return utils.InvalidSignedToken
+	}
+	return nil
+}
+
+func checkSendGridTimestamp(timestamp string, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return utils.InvalidSignedToken
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > sendGridTimestampTolerance || age < -sendGridTimestampTolerance {
+		log.Printf("Rejected SendGrid events signed at %s\n", time.Unix(seconds, 0).UTC().Format(time.RFC3339))
+		return utils.InvalidSignedToken
+	}
+	return nil
+}
+
+func getSendGridPublicKey() (*ecdsa.PublicKey, error) {
+	encodedKey := os.Getenv("SENDGRID_WEBHOOK_PUBLIC_KEY")
+	if encodedKey == "" {
+		log.Println("SENDGRID_WEBHOOK_PUBLIC_KEY is not set, SendGrid events are rejected")
+		return nil, utils.InvalidSignedToken
+	}
+	der, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		log.Printf("Failed to decode SendGrid public key: %v\n", err)
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		log.Printf("Failed to parse SendGrid public key: %v\n", err)
+		return nil, err
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		log.Println("SendGrid public key is not an ECDSA key")
+		return nil, utils.InvalidSignedToken
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,56 @@
+package emailNotifications
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+type SendGridMailer struct {
+	client *sendgrid.Client
+}
+
+func NewSendGridMailer(apiKey string) *SendGridMailer {
+	return &SendGridMailer{client: sendgrid.NewSendClient(apiKey)}
+}
+
+func (m *SendGridMailer) Send(message *Message) error {
+	sgMessage := mail.NewV3Mail()
+	sgMessage.SetFrom(mail.NewEmail(message.FromName, message.FromEmail))
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(mail.NewEmail(message.ToName, message.ToEmail))
+	for name, value := range message.Headers {
+		personalization.SetHeader(name, value)
+	}
+	if message.Id != "" {
+		personalization.SetCustomArg(outboxEmailIdArg, message.Id)
+	}
+	sgMessage.Subject = message.Subject
+	if message.Text != "" {
+		sgMessage.AddContent(mail.NewContent("text/plain", message.Text))
+	}
+	if message.HTML != "" {
+		sgMessage.AddContent(mail.NewContent("text/html", message.HTML))
+	}
+	sgMessage.AddPersonalizations(personalization)
+
+	for _, attachment := range message.Attachments {
+		sgAttachment := mail.NewAttachment()
+		sgAttachment.SetContent(base64.StdEncoding.EncodeToString(attachment.Content))
+		sgAttachment.SetType(attachment.ContentType)
+		sgAttachment.SetFilename(attachment.Filename)
+		sgAttachment.SetDisposition("attachment")
+		sgMessage.AddAttachment(sgAttachment)
+	}
+
+	response, err := m.client.Send(sgMessage)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("sendgrid responded with status %d: %s", response.StatusCode, response.Body)
+	}
+	return nil
+}
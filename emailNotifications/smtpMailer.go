@@ -0,0 +1,118 @@
+package emailNotifications
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+const defaultSMTPPort = "1025"
+
+// SMTPMailer sends messages over plain SMTP, e.g. to a local MailHog.
+type SMTPMailer struct {
+	address string
+	auth    smtp.Auth
+}
+
+func NewSMTPMailer(host, port, username, password string) *SMTPMailer {
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = defaultSMTPPort
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{address: net.JoinHostPort(host, port), auth: auth}
+}
+
+func (m *SMTPMailer) Send(message *Message) error {
+	body, err := buildMIMEMessage(message)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.address, m.auth, message.FromEmail, []string{message.ToEmail}, body)
+}
+
+func buildMIMEMessage(message *Message) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+
+	headers := map[string]string{
+		"From":         (&mail.Address{Name: message.FromName, Address: message.FromEmail}).String(),
+		"To":           (&mail.Address{Name: message.ToName, Address: message.ToEmail}).String(),
+		"Subject":      mime.QEncoding.Encode("utf-8", message.Subject),
+		"MIME-Version": "1.0",
+		"Content-Type": "multipart/mixed; boundary=" + writer.Boundary(),
+	}
+	for name, value := range message.Headers {
+		headers[name] = value
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buffer.WriteString(name + ": " + headers[name] + "\r\n")
+	}
+	buffer.WriteString("\r\n")
+
+	if err := writePart(writer, "text/plain; charset=utf-8", message.Text); err != nil {
+		return nil, err
+	}
+	if message.HTML != "" {
+		if err := writePart(writer, "text/html; charset=utf-8", message.HTML); err != nil {
+			return nil, err
+		}
+	}
+	for _, attachment := range message.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err = part.Write([]byte(wrapBase64(attachment.Content))); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writePart(writer *multipart.Writer, contentType, content string) error {
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = part.Write([]byte(wrapBase64([]byte(content))))
+	return err
+}
+
+func wrapBase64(content []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	var builder strings.Builder
+	for len(encoded) > 76 {
+		builder.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	builder.WriteString(encoded + "\r\n")
+	return builder.String()
+}
@@ -0,0 +1,45 @@
+package emailNotifications
+
+import (
+	"fmt"
+	"time"
+)
+
+// sampleTemplateData fills every key used by the templates, so previews show each block.
+func sampleTemplateData(language string) map[string]any {
+	return map[string]any{
+		"name":              "Alex Doe",
+		"mentorName":        "Maria Garcia",
+		"menteeName":        "Alex Doe",
+		"recipientName":     "Alex Doe",
+		"canceledByName":    "Maria Garcia",
+		"counterpartName":   "Maria Garcia",
+		"price":             "25 EUR",
+		"paymentDetails":    "25 EUR",
+		"sessionDate":       "Monday, 2 June 2025",
+		"sessionTime":       "18:00 - 19:00 (Europe/Berlin)",
+		"meetingLink":       "https://meet.google.com/abc-defg-hij",
+		"timeBeforeSession": formatTimeBeforeSession(30*time.Minute, language),
+		"sessionId":         "000000000000000000000000",
+		"reason":            "Something came up at work.",
+		"unsubscribeUrl":    "https://api.oystermentors.com/notifications/unsubscribe/sample",
+		"token":             "sample",
+		"verifyUrl":         "https://oystermentors.com/verifyEmail?token=sample",
+		"expiresIn":         formatTimeBeforeSession(time.Hour, language),
+	}
+}
+
+// RenderPreview renders the template with sample data. Format is "html" or "text".
+func RenderPreview(name, language, format string) (subject, body string, err error) {
+	if !IsSupportedLanguage(language) {
+		return "", "", fmt.Errorf("unsupported language %s", language)
+	}
+	rendered, err := renderTemplate(name, language, sampleTemplateData(language))
+	if err != nil {
+		return "", "", err
+	}
+	if format == "text" {
+		return rendered.Subject, rendered.Text, nil
+	}
+	return rendered.Subject, rendered.HTML, nil
+}
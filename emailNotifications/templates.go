@@ -0,0 +1,114 @@
+package emailNotifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	textTemplate "text/template"
+)
+
+const (
+	DefaultLanguage    = "en"
+	layoutTemplateName = "layout"
+	templateExtension  = ".tmpl"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+type renderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type emailTemplate struct {
+	text *textTemplate.Template
+	html *htmlTemplate.Template
+}
+
+// emailTemplates maps a language and a template name to the parsed template. Every template
+// file defines the "subject", "text" and "html" blocks and may use the blocks of the layout.
+var emailTemplates = mustParseTemplates()
+
+func mustParseTemplates() map[string]map[string]*emailTemplate {
+	languages, err := fs.ReadDir(templateFiles, "templates")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]map[string]*emailTemplate)
+	for _, language := range languages {
+		languageDir := path.Join("templates", language.Name())
+		files, err := fs.Glob(templateFiles, path.Join(languageDir, "*"+templateExtension))
+		if err != nil {
+			panic(err)
+		}
+		layoutFile := path.Join(languageDir, layoutTemplateName+templateExtension)
+		result[language.Name()] = make(map[string]*emailTemplate)
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), templateExtension)
+			if name == layoutTemplateName {
+				continue
+			}
+			result[language.Name()][name] = &emailTemplate{
+				text: textTemplate.Must(textTemplate.New(name).Option("missingkey=zero").ParseFS(templateFiles, layoutFile, file)),
+				html: htmlTemplate.Must(htmlTemplate.New(name).Option("missingkey=zero").ParseFS(templateFiles, layoutFile, file)),
+			}
+		}
+	}
+	return result
+}
+
+func IsSupportedLanguage(language string) bool {
+	_, ok := emailTemplates[language]
+	return ok
+}
+
+// TemplateNames lists the templates of the default language, every language has the same set.
+func TemplateNames() []string {
+	var names []string
+	for name := range emailTemplates[DefaultLanguage] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderTemplate renders the template in the language of the recipient and falls back to the
+// default language when the language is not supported.
+func renderTemplate(name, language string, data map[string]any) (*renderedEmail, error) {
+	templates, ok := emailTemplates[language]
+	if !ok {
+		templates = emailTemplates[DefaultLanguage]
+	}
+	emailTemplate, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("email template %s not found", name)
+	}
+	templateData := map[string]any{"appUrl": os.Getenv("FRONTEND_URL")}
+	for key, value := range data {
+		templateData[key] = value
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := emailTemplate.text.ExecuteTemplate(&subject, "subject", templateData); err != nil {
+		return nil, err
+	}
+	if err := emailTemplate.text.ExecuteTemplate(&text, "text", templateData); err != nil {
+		return nil, err
+	}
+	if err := emailTemplate.html.ExecuteTemplate(&html, "html", templateData); err != nil {
+		return nil, err
+	}
+	return &renderedEmail{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,34 @@
+package emailNotifications
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.example.com")
+	t.Setenv("ENV_URL", "https://api.example.com")
+	t.Setenv("LINK_SIGNING_SECRET", "test-secret")
+	for language := range emailTemplates {
+		for _, name := range TemplateNames() {
+			t.Run(language+"/"+name, func(t *testing.T) {
+				if _, ok := emailTemplates[language][name]; !ok {
+					t.Fatalf("template %s is missing", name)
+				}
+				rendered, err := renderTemplate(name, language, sampleTemplateData(language))
+				if err != nil {
+					t.Fatalf("render failed: %v", err)
+				}
+				if strings.TrimSpace(rendered.Subject) == "" {
+					t.Error("subject is empty")
+				}
+				if strings.TrimSpace(rendered.Text) == "" {
+					t.Error("text body is empty")
+				}
+				if strings.TrimSpace(rendered.HTML) == "" {
+					t.Error("html body is empty")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package emailNotifications
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"os"
+	"oysterProject/database"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"strings"
+)
+
+const unsubscribeTokenPrefix = "unsubscribe"
+
+// NewUnsubscribeToken signs the user and the category, so the link works without logging in.
+func NewUnsubscribeToken(userId primitive.ObjectID, category model.NotificationCategory) string {
+	return utils.SignToken(strings.Join([]string{unsubscribeTokenPrefix, userId.Hex(), string(category)}, ":"))
+}
+
+func ParseUnsubscribeToken(token string) (primitive.ObjectID, model.NotificationCategory, error) {
+	payload, err := utils.VerifySignedToken(token)
+	if err != nil {
+		return primitive.NilObjectID, "", err
+	}
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 || parts[0] != unsubscribeTokenPrefix {
+		return primitive.NilObjectID, "", utils.InvalidSignedToken
+	}
+	userId, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return primitive.NilObjectID, "", utils.InvalidSignedToken
+	}
+	return userId, model.NotificationCategory(parts[2]), nil
+}
+
+func getUnsubscribeURL(userId primitive.ObjectID, category model.NotificationCategory) string {
+	return os.Getenv("ENV_URL") + "/notifications/unsubscribe/" + NewUnsubscribeToken(userId, category)
+}
+
+// wantsEmail checks the deliverability of the address and the notification preferences of the
+// recipient. The email is sent when they cannot be read, a missed session email is worse than
+// an unwanted one.
+func wantsEmail(to recipient, category model.NotificationCategory) bool {
+	if undeliverable, err := database.IsEmailUndeliverable(to.email); err == nil && undeliverable {
+		log.Printf("Email of category %s to %s skipped, the address is undeliverable\n", category, to.email)
+		return false
+	}
+	if to.userId.IsZero() {
+		return true
+	}
+	preferences, err := database.GetNotificationPreferences(to.userId)
+	if err != nil {
+		return true
+	}
+	if !preferences.IsEnabled(category, model.ChannelEmail) {
+		log.Printf("Email of category %s to user(%s) skipped by notification preferences\n", category, to.userId.Hex())
+		return false
+	}
+	return true
+}
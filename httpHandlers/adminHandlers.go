@@ -0,0 +1,147 @@
+package httpHandlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"mime"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/emailNotifications"
+	"oysterProject/model"
+	"oysterProject/schedulerJobs"
+	"oysterProject/utils"
+	"strconv"
+)
+
+const (
+	defaultJobRunsLimit    = 50
+	maxJobRunsLimit        = 500
+	defaultUserEmailsLimit = 100
+	maxUserEmailsLimit     = 1000
+)
+
+func GetJobs(w http.ResponseWriter, r *http.Request) {
+	var jobs []*model.JobInfo
+	for _, jobName := range schedulerJobs.GetJobNames() {
+		interval, nextRun, err := schedulerJobs.GetJobSchedule(jobName)
+		if err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting job schedule")
+			return
+		}
+		lastRun, err := database.GetLastJobRun(jobName)
+		if err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting job runs from database")
+			return
+		}
+		jobInfo := &model.JobInfo{
+			Name:     jobName,
+			Interval: interval.String(),
+			NextRun:  nextRun,
+			LastRun:  lastRun,
+			Running:  lastRun != nil && lastRun.Status == model.JobRunRunning,
+		}
+		if jobLock, err := database.GetJobLock(jobName); err == nil {
+			jobInfo.Lock = jobLock
+		}
+		jobs = append(jobs, jobInfo)
+	}
+	writeJSONResponse(w, r, http.StatusOK, jobs)
+}
+
+func GetJobRuns(w http.ResponseWriter, r *http.Request) {
+	limit, err := getLimitParameter(r, defaultJobRunsLimit, maxJobRunsLimit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+	runs, err := database.GetJobRuns(r.URL.Query().Get("jobName"), limit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting job runs from database")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, runs)
+}
+
+// GetUserEmails lists the emails queued and sent to the user, for support requests.
+func GetUserEmails(w http.ResponseWriter, r *http.Request) {
+	userId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "userId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+	limit, err := getLimitParameter(r, defaultUserEmailsLimit, maxUserEmailsLimit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+	emails, err := database.GetUserEmails(userId, limit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting emails from database")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, emails)
+}
+
+func getLimitParameter(r *http.Request, defaultLimit, maxLimit int64) (int64, error) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.ParseInt(limitParam, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if limit <= 0 || limit > maxLimit {
+		return 0, errors.New("limit out of range")
+	}
+	return limit, nil
+}
+
+func TriggerJob(w http.ResponseWriter, r *http.Request) {
+	jobName := chi.URLParam(r, "jobName")
+	err := schedulerJobs.TriggerJob(jobName)
+	if errors.Is(err, utils.JobNotFound) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Job not found")
+		return
+	} else if errors.Is(err, utils.JobAlreadyRunning) {
+		writeMessageResponse(w, r, http.StatusConflict, "Job "+jobName+" is already running")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error triggering job")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusAccepted, "Job "+jobName+" triggered")
+}
+
+func GetEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, r, http.StatusOK, emailNotifications.TemplateNames())
+}
+
+// PreviewEmailTemplate renders ?template= in ?language= (default en) with sample data,
+// as HTML or as plain text with ?format=text.
+func PreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	queryParameters := r.URL.Query()
+	language := queryParameters.Get("language")
+	if language == "" {
+		language = emailNotifications.DefaultLanguage
+	}
+	format := queryParameters.Get("format")
+	if format != "" && format != "html" && format != "text" {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid format")
+		return
+	}
+	subject, body, err := emailNotifications.RenderPreview(queryParameters.Get("template"), language, format)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error rendering template: "+err.Error())
+		return
+	}
+	contentType := "text/html; charset=utf-8"
+	if format == "text" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Email-Subject", mime.QEncoding.Encode("utf-8", subject))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
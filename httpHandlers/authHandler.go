@@ -17,6 +17,7 @@ import (
 	"net/mail"
 	"os"
 	"oysterProject/database"
+	"oysterProject/emailNotifications"
 	"oysterProject/model"
 	"oysterProject/utils"
 	"strconv"
@@ -67,7 +68,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		expiresAt := time.Now().Add(expirationTime)
-		userSession, err := database.UpdateAuthSession(sessionId, expiresAt)
+		userSession, err := database.UpdateAuthSession(sessionId, expiresAt, getClientIP(r), getUserAgent(r))
 		if err != nil {
 			writeMessageResponse(w, r, http.StatusUnauthorized, "User unauthorized")
 			return
@@ -79,6 +80,23 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// AdminMiddleware lets through users with the admin flag, it must run after AuthMiddleware.
+func AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userSession := getUserSessionFromRequest(r)
+		if userSession == nil {
+			writeMessageResponse(w, r, http.StatusUnauthorized, "User unauthorized")
+			return
+		}
+		user, err := database.GetUserByID(userSession.UserId)
+		if err != nil || !user.IsAdmin {
+			writeMessageResponse(w, r, http.StatusForbidden, "Admin access required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func SignIn(w http.ResponseWriter, r *http.Request) {
 	var credentials model.Auth
 	if err := render.DecodeJSON(r.Body, &credentials); err != nil {
@@ -99,10 +117,28 @@ func SignIn(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusUnauthorized, "Wrong password")
 		return
 	}
-	expiresAt := time.Now().Add(expirationTime)
+	signInUser(w, r, user, "Sign in successful")
+}
+
+// signInUser starts an auth session, or a two-factor challenge for users with 2FA enabled.
+func signInUser(w http.ResponseWriter, r *http.Request, user *model.User, message string) {
+	if user.IsTwoFactorEnabled() {
+		writeTwoFactorChallenge(w, r, user)
+		return
+	}
+	writeNewAuthSession(w, r, user.Id, message)
+}
+
+func writeNewAuthSession(w http.ResponseWriter, r *http.Request, userId primitive.ObjectID, message string) {
+	now := time.Now()
+	expiresAt := now.Add(expirationTime)
 	sessionId, err := database.SaveAuthSession(&model.AuthSession{
-		UserId: user.Id,
-		Expiry: expiresAt.Unix(),
+		UserId:     userId,
+		Expiry:     expiresAt.Unix(),
+		CreatedAt:  utils.TimePtr(now.UTC()),
+		LastSeenAt: utils.TimePtr(now.UTC()),
+		IP:         getClientIP(r),
+		UserAgent:  getUserAgent(r),
 	})
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Database saving session error")
@@ -110,7 +146,7 @@ func SignIn(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeHeaderValue(w, SessionHeaderName, sessionId)
-	writeMessageResponse(w, r, http.StatusOK, "Sign in successful")
+	writeMessageResponse(w, r, http.StatusOK, message)
 }
 
 func SignOut(w http.ResponseWriter, r *http.Request) {
@@ -141,6 +177,14 @@ func HandleEmailPassAuth(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Email is not valid")
 		return
 	}
+	authData.Email = utils.NormalizeEmail(authData.Email)
+	if _, err = database.GetUserByEmail(authData.Email); err == nil {
+		writeMessageResponse(w, r, http.StatusConflict, utils.UserAlreadyExists.Error())
+		return
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Database search error")
+		return
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(authData.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -156,26 +200,20 @@ func HandleEmailPassAuth(w http.ResponseWriter, r *http.Request) {
 		ApprovedEmailWasSent: false,
 		IsPublic:             true,
 		UserRegisterDate:     utils.TimePtr(time.Now()),
+		EmailVerified:        utils.BoolPtr(false),
+		VerificationEmailAt:  utils.TimePtr(time.Now()),
 	}
 
 	user.Id, err = database.CreateUser(&user)
-	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Error inserting user into database")
+	if errors.Is(err, utils.UserAlreadyExists) {
+		writeMessageResponse(w, r, http.StatusConflict, err.Error())
 		return
-	}
-
-	expiresAt := time.Now().Add(expirationTime)
-	sessionId, err := database.SaveAuthSession(&model.AuthSession{
-		UserId: user.Id,
-		Expiry: expiresAt.Unix(),
-	})
-	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Database saving session error")
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error inserting user into database")
 		return
 	}
-
-	writeHeaderValue(w, SessionHeaderName, sessionId)
-	writeMessageResponse(w, r, http.StatusOK, "Sign up successful")
+	go emailNotifications.SendVerificationEmail(&user)
+	writeNewAuthSession(w, r, user.Id, "Sign up successful")
 }
 
 func generateStateOauthCookie(w http.ResponseWriter) (string, error) {
@@ -245,6 +283,7 @@ func HandleAuthCallback(w http.ResponseWriter, r *http.Request) {
 		userInfo.ApprovedEmailWasSent = false
 		userInfo.IsPublic = true
 		userInfo.UserRegisterDate = utils.TimePtr(time.Now())
+		userInfo.EmailVerified = utils.BoolPtr(true)
 		userInfo.AsMentor, err = strconv.ParseBool(r.FormValue("asMentor"))
 		if err != nil {
 			log.Println("HandleAuthCallback: asMentor flag was not received. Default false")
@@ -255,23 +294,22 @@ func HandleAuthCallback(w http.ResponseWriter, r *http.Request) {
 			writeMessageResponse(w, r, http.StatusInternalServerError, "Database insert error: "+err.Error())
 			return
 		}
+		user = userInfo
 	} else if err != nil {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Database search error: "+err.Error())
 		return
+	} else if !user.IsEmailVerified() {
+		// Google has confirmed the address, so the password and sessions of whoever registered it
+		// unverified must not keep access to the account.
+		err = database.TakeOverUnverifiedAccount(user.Id, user.Email)
+		if err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Database update error: "+err.Error())
+			return
+		}
+		user.Password = ""
+		user.TwoFactor = nil
 	}
-
-	expiresAt := time.Now().Add(expirationTime)
-	sessionId, err := database.SaveAuthSession(&model.AuthSession{
-		UserId: user.Id,
-		Expiry: expiresAt.Unix(),
-	})
-	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Database saving session error")
-		return
-	}
-
-	writeHeaderValue(w, SessionHeaderName, sessionId)
-	writeMessageResponse(w, r, http.StatusOK, "Sign up successful")
+	signInUser(w, r, user, "Sign up successful")
 }
 
 func ChangePassword(w http.ResponseWriter, r *http.Request) {
@@ -293,6 +331,10 @@ func ChangePassword(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating password")
 		return
 	}
+	if _, err = database.DeleteOtherAuthSessions(userSession.UserId, userSession.SessionId); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Password updated, but other sessions were not signed out")
+		return
+	}
 	writeMessageResponse(w, r, http.StatusOK, "Password successfully updated")
 }
 
@@ -303,7 +345,7 @@ func RefreshAuthSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	expiresAt := time.Now().Add(expirationTime)
-	_, err := database.UpdateAuthSession(userSession.SessionId, expiresAt)
+	_, err := database.UpdateAuthSession(userSession.SessionId, expiresAt, getClientIP(r), getUserAgent(r))
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error updating auth session")
 		return
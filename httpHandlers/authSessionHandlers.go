@@ -0,0 +1,64 @@
+package httpHandlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"oysterProject/database"
+)
+
+// GetAuthSessions lists the devices the user is signed in on, the session of the request is
+// marked as current.
+func GetAuthSessions(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	sessions, err := database.GetUserAuthSessions(userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting sessions from database")
+		return
+	}
+	for _, session := range sessions {
+		session.Current = session.SessionId == userSession.SessionId
+	}
+	writeJSONResponse(w, r, http.StatusOK, sessions)
+}
+
+func RevokeAuthSession(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	publicId := chi.URLParam(r, "sessionId")
+	if publicId == "" {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid session id")
+		return
+	}
+	err := database.DeleteUserAuthSession(userSession.UserId, publicId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Session not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error deleting session")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Session signed out")
+}
+
+// RevokeOtherAuthSessions signs the user out everywhere except the device of the request.
+func RevokeOtherAuthSessions(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	if _, err := database.DeleteOtherAuthSessions(userSession.UserId, userSession.SessionId); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error deleting sessions")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Signed out everywhere else")
+}
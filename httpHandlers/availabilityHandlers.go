@@ -0,0 +1,105 @@
+package httpHandlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/model"
+)
+
+func GetAvailabilityExceptions(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	user, err := database.GetUserByID(userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	exceptions := user.AvailabilityExceptions
+	if exceptions == nil {
+		exceptions = []*model.AvailabilityException{}
+	}
+	writeJSONResponse(w, r, http.StatusOK, exceptions)
+}
+
+func CreateAvailabilityException(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	var exception model.AvailabilityException
+	if err := parseJSONRequest(r, &exception); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if err := model.ValidateAvailabilityException(&exception); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := database.AddAvailabilityException(userSession.UserId, &exception); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error saving availability exception")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusCreated, exception)
+}
+
+func UpdateAvailabilityException(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	exceptionId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "exceptionId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid exception id")
+		return
+	}
+	var exception model.AvailabilityException
+	if err = parseJSONRequest(r, &exception); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if err = model.ValidateAvailabilityException(&exception); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	exception.Id = exceptionId
+	err = database.UpdateAvailabilityException(userSession.UserId, &exception)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Availability exception not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating availability exception")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, exception)
+}
+
+func DeleteAvailabilityException(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	exceptionId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "exceptionId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid exception id")
+		return
+	}
+	err = database.DeleteAvailabilityException(userSession.UserId, exceptionId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Availability exception not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error deleting availability exception")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Availability exception deleted")
+}
@@ -0,0 +1,73 @@
+package httpHandlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"os"
+	"oysterProject/calendar"
+	"oysterProject/database"
+	"oysterProject/utils"
+)
+
+const calendarTokenSize = 32
+
+type CalendarFeed struct {
+	CalendarURL string `json:"calendarUrl"`
+}
+
+func GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		writeMessageResponse(w, r, http.StatusNotFound, "Calendar not found")
+		return
+	}
+	user, err := database.GetUserByCalendarToken(token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Calendar not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting calendar from database")
+		return
+	}
+	sessions, err := database.GetUserCalendarSessions(user.Id)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting user sessions from database")
+		return
+	}
+
+	feed := &calendar.Calendar{Name: "Oyster sessions"}
+	for _, session := range sessions {
+		if calendar.IsSessionInFeed(session) {
+			feed.Events = append(feed.Events, calendar.NewSessionEvent(session, user.Id))
+		}
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(feed.Bytes())
+}
+
+// RotateCalendarToken issues a new feed token, the previous feed URL stops working.
+func RotateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	token, err := utils.GenerateRandomToken(calendarTokenSize)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Failed to generate calendar token")
+		return
+	}
+	if err = database.UpdateCalendarToken(userSession.UserId, token); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error saving calendar token")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, CalendarFeed{CalendarURL: getCalendarURL(token)})
+}
+
+func getCalendarURL(token string) string {
+	return os.Getenv("ENV_URL") + "/calendar/" + token + ".ics"
+}
@@ -0,0 +1,61 @@
+package httpHandlers
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/emailNotifications"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"strconv"
+	"time"
+)
+
+const verificationEmailThrottle = 2 * time.Minute
+
+// VerifyEmail confirms the address with the token from the verification link.
+func VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var request model.Token
+	if err := parseJSONRequest(r, &request); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	userId, email, err := emailNotifications.ParseEmailVerificationToken(request.Token, time.Now())
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Verification link is invalid or expired")
+		return
+	}
+	err = database.VerifyEmail(userId, email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Email was changed after the link was sent")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Email verified")
+}
+
+// ResendVerificationEmail sends a new verification link, at most once per throttle interval.
+func ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	user, err := database.ClaimVerificationEmail(userSession.UserId, time.Now().UTC(), verificationEmailThrottle)
+	if errors.Is(err, utils.EmailAlreadyVerified) {
+		writeMessageResponse(w, r, http.StatusConflict, err.Error())
+		return
+	} else if errors.Is(err, utils.VerificationEmailThrottled) {
+		writeHeaderValue(w, "Retry-After", strconv.Itoa(int(verificationEmailThrottle/time.Second)))
+		writeMessageResponse(w, r, http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error reading user from database")
+		return
+	}
+	go emailNotifications.SendVerificationEmail(user)
+	writeMessageResponse(w, r, http.StatusOK, "Verification email sent")
+}
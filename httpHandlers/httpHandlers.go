@@ -12,6 +12,7 @@ import (
 	"oysterProject/model"
 	"oysterProject/utils"
 	"strconv"
+	"time"
 )
 
 func GetMentorsList(w http.ResponseWriter, r *http.Request) {
@@ -127,6 +128,8 @@ func GetProfileByToken(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
 		return
 	}
+	user.EmailVerified = utils.BoolPtr(user.IsEmailVerified())
+	user.TwoFactorEnabled = user.IsTwoFactorEnabled()
 	writeJSONResponse(w, r, http.StatusOK, user)
 }
 
@@ -148,18 +151,74 @@ func UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
 			writeMessageResponse(w, r, http.StatusBadRequest, "Email is not valid")
 			return
 		}
+		userForUpdate.Email = utils.NormalizeEmail(userForUpdate.Email)
 	}
 
-	updateUsersTimezoneTime(&userForUpdate)
+	if userForUpdate.PreferredLanguage != "" && !emailNotifications.IsSupportedLanguage(userForUpdate.PreferredLanguage) {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Unsupported preferred language")
+		return
+	}
+	if err := validateUserTimeZones(&userForUpdate); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid availability: "+err.Error())
+		return
+	}
+	for _, sessionType := range userForUpdate.SessionTypes {
+		if err := model.ValidateSessionType(sessionType); err != nil {
+			writeMessageResponse(w, r, http.StatusBadRequest, "Invalid session type: "+err.Error())
+			return
+		}
+	}
+
+	if userForUpdate.BookingRules != nil {
+		if err := model.ValidateBookingRules(userForUpdate.BookingRules); err != nil {
+			writeMessageResponse(w, r, http.StatusBadRequest, "Invalid booking rules: "+err.Error())
+			return
+		}
+	}
+
+	userForUpdate.AvailabilityExceptions = nil
+	userForUpdate.IsAdmin = false
+	userForUpdate.EmailDeliverability = nil
+	userForUpdate.EmailVerified = nil
+	userForUpdate.VerificationEmailAt = nil
+
+	var userToVerify *model.User
+	if userForUpdate.Email != "" {
+		currentUser, err := database.GetUserByID(userSession.UserId)
+		if err != nil {
+			writeMessageResponse(w, r, http.StatusNotFound, "User not found")
+			return
+		}
+		if currentUser.Email != userForUpdate.Email {
+			if _, err := database.GetUserByEmail(userForUpdate.Email); err == nil {
+				writeMessageResponse(w, r, http.StatusConflict, utils.UserAlreadyExists.Error())
+				return
+			}
+			userForUpdate.EmailVerified = utils.BoolPtr(false)
+			userForUpdate.VerificationEmailAt = utils.TimePtr(time.Now())
+			userToVerify = currentUser
+			userToVerify.Email = userForUpdate.Email
+		}
+	}
 
 	mentorRequest := userForUpdate.UserMentorRequest
 	userForUpdate.UserMentorRequest = ""
 
 	userAfterUpdate, err := database.UpdateAndGetUser(&userForUpdate, userSession.UserId)
-	if err != nil {
+	if errors.Is(err, utils.UserAlreadyExists) {
+		writeMessageResponse(w, r, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
 		return
 	}
+	if userToVerify != nil {
+		// bounces of the previous address must not block emails to the new one
+		if err = database.ResetEmailDeliverability(userSession.UserId); err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
+			return
+		}
+	}
 	userForExperienceUpdate := &model.User{}
 	for _, entry := range userAfterUpdate.AreaOfExpertise {
 		userForExperienceUpdate.Experience += entry.Experience
@@ -174,6 +233,9 @@ func UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
 		return
 	}
+	if userToVerify != nil {
+		go emailNotifications.SendVerificationEmail(userToVerify)
+	}
 
 	if len(mentorRequest) > 0 {
 		go func() {
@@ -184,19 +246,19 @@ func UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, r, http.StatusOK, userForExperienceUpdate)
 }
 
-func updateUsersTimezoneTime(user *model.User) {
-	if user.LatestTimeZone != 0 {
-		user.LatestTimeZone = -user.LatestTimeZone
-	}
-	if user.Availability == nil {
-		return
+func validateUserTimeZones(user *model.User) error {
+	if user.TimeZone != "" {
+		if _, err := time.LoadLocation(user.TimeZone); err != nil {
+			log.Printf("validateUserTimeZones: invalid user time zone(%s): %v\n", user.TimeZone, err)
+			return utils.InvalidTimeZone
+		}
 	}
 	for _, availability := range user.Availability {
-		err := model.UpdateTimezoneTime(availability)
-		if err != nil {
-			continue
+		if err := model.ValidateAvailability(availability, user.TimeZone); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 func GetTopMentors(w http.ResponseWriter, r *http.Request) {
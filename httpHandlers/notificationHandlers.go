@@ -0,0 +1,174 @@
+package httpHandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/model"
+	"time"
+)
+
+const (
+	defaultNotificationsLimit   = 50
+	maxNotificationsLimit       = 200
+	notificationsStreamInterval = 5 * time.Second
+)
+
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	limit, err := getLimitParameter(r, defaultNotificationsLimit, maxNotificationsLimit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	notifications, err := database.GetNotifications(userSession.UserId, unreadOnly, limit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting notifications from database")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, notifications)
+}
+
+func GetUnreadNotificationsCount(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	count, err := database.CountUnreadNotifications(r.Context(), userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error counting notifications")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, model.UnreadNotifications{Count: count})
+}
+
+func MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	notificationId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "notificationId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid notification id")
+		return
+	}
+	err = database.MarkNotificationRead(userSession.UserId, notificationId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Notification not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating notification")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Notification marked as read")
+}
+
+func MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	if _, err := database.MarkAllNotificationsRead(userSession.UserId); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating notifications")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Notifications marked as read")
+}
+
+// StreamNotifications sends Server-Sent Events: "unreadCount" on connect and whenever the count
+// changes, and "notification" for every new notification. The database is polled, so events
+// written by any instance reach the client. The auth header is required, so browsers connect
+// with fetch instead of EventSource. The auth session is checked on every poll, the stream is
+// closed once it was signed out or expired.
+func StreamNotifications(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+	ctx := r.Context()
+	lastId, err := database.GetLatestNotificationId(ctx, userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error reading notifications")
+		return
+	}
+	unreadCount, err := database.CountUnreadNotifications(ctx, userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error counting notifications")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err = writeServerSentEvent(w, "unreadCount", model.UnreadNotifications{Count: unreadCount}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(notificationsStreamInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, ok := database.FindAuthSession(userSession.SessionId); !ok {
+			return
+		}
+		notifications, err := database.GetNotificationsAfter(ctx, userSession.UserId, lastId)
+		if err != nil {
+			continue
+		}
+		for _, notification := range notifications {
+			if err = writeServerSentEvent(w, "notification", notification); err != nil {
+				return
+			}
+			lastId = notification.Id
+		}
+		count, countErr := database.CountUnreadNotifications(ctx, userSession.UserId)
+		if countErr == nil && count != unreadCount {
+			unreadCount = count
+			err = writeServerSentEvent(w, "unreadCount", model.UnreadNotifications{Count: unreadCount})
+		} else if len(notifications) == 0 {
+			// keeps proxies from closing an idle connection
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal %s event: %v\n", event, err)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,98 @@
+package httpHandlers
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"html"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/emailNotifications"
+	"oysterProject/model"
+)
+
+const unsubscribePage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Oyster</title></head>
+<body style="font-family: Arial, sans-serif; max-width: 600px; margin: 48px auto; padding: 0 24px;">
+%s
+</body>
+</html>`
+
+func GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	preferences, err := database.GetNotificationPreferences(userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting notification preferences from database")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, preferences.WithDefaults())
+}
+
+// UpdateNotificationPreferences changes the categories and channels present in the request,
+// e.g. {"categories": {"reminders": {"email": false}}}.
+func UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	var preferences model.NotificationPreferences
+	if err := parseJSONRequest(r, &preferences); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if err := model.ValidateNotificationPreferences(&preferences); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	updatedPreferences, err := database.UpdateNotificationPreferences(userSession.UserId, preferences.Categories)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error saving notification preferences")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, updatedPreferences.WithDefaults())
+}
+
+// GetUnsubscribePage asks to confirm the unsubscribe, so link scanners opening the URL from
+// an email do not unsubscribe the user.
+func GetUnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	_, category, err := emailNotifications.ParseUnsubscribeToken(chi.URLParam(r, "token"))
+	if err != nil {
+		writeUnsubscribePage(w, http.StatusBadRequest, "<p>This unsubscribe link is invalid.</p>")
+		return
+	}
+	writeUnsubscribePage(w, http.StatusOK, `<p>Stop receiving `+html.EscapeString(string(category))+` emails from Oyster?</p>
+<form method="post"><button type="submit">Unsubscribe</button></form>`)
+}
+
+// Unsubscribe turns off the email channel of the category in the token. It serves the
+// confirmation form and RFC 8058 one-click requests from mail clients.
+func Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	userId, category, err := emailNotifications.ParseUnsubscribeToken(chi.URLParam(r, "token"))
+	if err != nil {
+		writeUnsubscribePage(w, http.StatusBadRequest, "<p>This unsubscribe link is invalid.</p>")
+		return
+	}
+	categories := map[model.NotificationCategory]map[model.NotificationChannel]bool{
+		category: {model.ChannelEmail: false},
+	}
+	if err = model.ValidateNotificationPreferences(&model.NotificationPreferences{Categories: categories}); err != nil {
+		writeUnsubscribePage(w, http.StatusBadRequest, "<p>This unsubscribe link is invalid.</p>")
+		return
+	}
+	if _, err = database.UpdateNotificationPreferences(userId, categories); err != nil {
+		writeUnsubscribePage(w, http.StatusInternalServerError, "<p>Something went wrong, please try again later.</p>")
+		return
+	}
+	writeUnsubscribePage(w, http.StatusOK, "<p>You will no longer receive "+html.EscapeString(string(category))+" emails from Oyster.</p>")
+}
+
+func writeUnsubscribePage(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, unsubscribePage, body)
+}
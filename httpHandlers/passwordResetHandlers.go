@@ -0,0 +1,84 @@
+package httpHandlers
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"net/http"
+	"net/mail"
+	"oysterProject/database"
+	"oysterProject/emailNotifications"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+const (
+	passwordResetTokenSize = 32
+	forgotPasswordResponse = "If an account with this email exists, a password reset link was sent"
+)
+
+// ForgotPassword emails a reset link. The response is the same whether the account exists or
+// not, so the endpoint cannot be used to find out who is registered.
+func ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var request model.ForgotPassword
+	if err := parseJSONRequest(r, &request); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if _, err := mail.ParseAddress(request.Email); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Email is not valid")
+		return
+	}
+	user, err := database.GetUserByEmail(request.Email)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("ForgotPassword: error finding user: %v\n", err)
+		}
+		writeMessageResponse(w, r, http.StatusOK, forgotPasswordResponse)
+		return
+	}
+	token, err := utils.GenerateRandomToken(passwordResetTokenSize)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusOK, forgotPasswordResponse)
+		return
+	}
+	now := time.Now().UTC()
+	err = database.SavePasswordResetToken(&model.PasswordResetToken{
+		TokenHash: utils.HashToken(token),
+		UserId:    user.Id,
+		CreatedAt: now,
+		ExpiresAt: now.Add(model.PasswordResetTokenTTL),
+	})
+	if err == nil {
+		go emailNotifications.SendPasswordResetEmail(user, token)
+	}
+	writeMessageResponse(w, r, http.StatusOK, forgotPasswordResponse)
+}
+
+// ResetPassword sets the new password with a token from the reset email and revokes every
+// auth session of the user.
+func ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var request model.PasswordReset
+	if err := parseJSONRequest(r, &request); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if request.Token == "" || request.NewPassword == "" {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Token and new password are required")
+		return
+	}
+	token, err := database.ConsumePasswordResetToken(utils.HashToken(request.Token), time.Now().UTC())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Reset link is invalid or expired")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error reading reset token from database")
+		return
+	}
+	if err := database.ResetPassword(token.UserId, request.NewPassword); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating password")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Password successfully reset")
+}
@@ -5,12 +5,17 @@ import (
 	"github.com/go-chi/render"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"oysterProject/model"
 	"oysterProject/utils"
+	"strings"
 	"time"
 )
 
+const maxUserAgentLength = 512
+
 func parseJSONRequest(r *http.Request, payload interface{}) error {
 	err := json.NewDecoder(r.Body).Decode(payload)
 	if err != nil && err != io.EOF {
@@ -66,3 +71,78 @@ func wrapResponseBody(payload interface{}, r *http.Request) *model.ApiResponse {
 	}
 	return apiResponse
 }
+
+// trustedProxies lists the networks of the load balancers in TRUSTED_PROXIES, comma separated
+// addresses or CIDRs. X-Forwarded-For is ignored unless the connection comes from one of them.
+var trustedProxies = parseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+
+func parseTrustedProxies(value string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid trusted proxy %q: %v\n", entry, err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// getClientIP returns the address of the connection. Behind a trusted proxy it walks
+// X-Forwarded-For from the right and returns the first hop which is not a trusted proxy, since
+// the entries on the left are set by the client.
+func getClientIP(r *http.Request) string {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+	if !isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+	clientIP := remoteIP
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		clientIP = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return clientIP
+}
+
+func getUserAgent(r *http.Request) string {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
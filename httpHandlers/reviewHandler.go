@@ -4,21 +4,16 @@ import (
 	"github.com/go-chi/chi/v5"
 	"net/http"
 	"oysterProject/database"
+	"oysterProject/inAppNotifications"
 	"oysterProject/model"
+	"oysterProject/webhooks"
 )
 
 func CreateSessionReview(w http.ResponseWriter, r *http.Request) {
-	sessionId := chi.URLParam(r, "sessionId")
-	sessionChan := make(chan *model.Session)
-	errChan := make(chan error)
-	go func() {
-		session, err := database.GetMentorMenteeIdsBySessionId(sessionId)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		sessionChan <- session
-	}()
+	session, _, ok := loadSessionForParticipant(w, r, chi.URLParam(r, "sessionId"))
+	if !ok {
+		return
+	}
 
 	var sessionReview model.Review
 	err := parseJSONRequest(r, &sessionReview)
@@ -26,23 +21,14 @@ func CreateSessionReview(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON session review")
 		return
 	}
-
-	var session *model.Session
-	select {
-	case sessionFromChan := <-sessionChan:
-		session = sessionFromChan
-	case errFromChan := <-errChan:
-		if errFromChan != nil {
-			writeMessageResponse(w, r, http.StatusNotFound, "Session not found")
-			return
-		}
-	}
 	sessionReview.FillDefaultsSessionReview(session)
 	err = database.CreateReview(&sessionReview)
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error creating review")
 		return
 	}
+	go inAppNotifications.NotifyReviewCreated(&sessionReview)
+	go webhooks.EmitReviewCreated(&sessionReview)
 	writeJSONResponse(w, r, http.StatusCreated, sessionReview)
 }
 
@@ -59,5 +45,7 @@ func CreatePublicReview(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error creating review")
 		return
 	}
+	go inAppNotifications.NotifyReviewCreated(&sessionReview)
+	go webhooks.EmitReviewCreated(&sessionReview)
 	writeJSONResponse(w, r, http.StatusCreated, sessionReview)
 }
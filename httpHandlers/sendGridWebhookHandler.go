@@ -0,0 +1,65 @@
+package httpHandlers
+
+import (
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"log"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/emailNotifications"
+	"oysterProject/model"
+	"time"
+)
+
+const maxSendGridEventsSize = 10 << 20
+
+// HandleSendGridEvents stores the delivery events of the signed SendGrid event webhook and marks
+// addresses undeliverable after a hard bounce or a spam report. SendGrid retries on any non-2xx
+// response, so only a bad signature or body is rejected.
+func HandleSendGridEvents(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxSendGridEventsSize))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error reading request body")
+		return
+	}
+	signature := r.Header.Get(emailNotifications.SendGridSignatureHeader)
+	timestamp := r.Header.Get(emailNotifications.SendGridTimestampHeader)
+	if err := emailNotifications.VerifySendGridSignature(signature, timestamp, payload); err != nil {
+		writeMessageResponse(w, r, http.StatusUnauthorized, "Invalid signature")
+		return
+	}
+	var events []*model.SendGridEvent
+	if err := json.Unmarshal(payload, &events); err != nil {
+		log.Printf("HandleSendGridEvents: error parsing events: %v\n", err)
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	receivedAt := time.Now().UTC()
+	var validEvents []*model.SendGridEvent
+	for _, event := range events {
+		if event.EventId == "" {
+			continue
+		}
+		event.ReceivedAt = receivedAt
+		if id, err := primitive.ObjectIDFromHex(event.OutboxEmailIdHex); err == nil {
+			event.OutboxEmailId = id
+		}
+		validEvents = append(validEvents, event)
+	}
+	if err := database.SaveEmailEvents(validEvents); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error saving email events")
+		return
+	}
+	for _, event := range validEvents {
+		deliverability := event.GetDeliverability()
+		if deliverability == nil {
+			continue
+		}
+		if err := database.MarkEmailUndeliverable(event.Email, deliverability); err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating email deliverability")
+			return
+		}
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Events received")
+}
@@ -0,0 +1,41 @@
+package httpHandlers
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/model"
+)
+
+// loadSessionForParticipant resolves the session and checks that the caller is its mentor or mentee.
+// When false is returned the error response has already been written.
+func loadSessionForParticipant(w http.ResponseWriter, r *http.Request, sessionId string) (*model.Session, model.SessionRole, bool) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return nil, 0, false
+	}
+	sessionIdObj, err := primitive.ObjectIDFromHex(sessionId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Session id invalid")
+		return nil, 0, false
+	}
+	session, err := database.GetSessionByID(sessionIdObj)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Session not found")
+		return nil, 0, false
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error during session search")
+		return nil, 0, false
+	}
+	role, ok := session.GetParticipantRole(userSession.UserId)
+	if !ok {
+		log.Printf("User(%s) is not a participant of session(%s)\n", userSession.UserId.Hex(), sessionId)
+		writeMessageResponse(w, r, http.StatusForbidden, "User is not a participant of the session")
+		return nil, 0, false
+	}
+	return session, role, true
+}
@@ -1,21 +1,25 @@
 package httpHandlers
 
 import (
+	"errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/exp/slices"
+	"go.mongodb.org/mongo-driver/mongo"
+	"io"
 	"log"
 	"net/http"
 	"oysterProject/database"
 	"oysterProject/emailNotifications"
+	"oysterProject/inAppNotifications"
 	"oysterProject/model"
 	"oysterProject/utils"
+	"oysterProject/webhooks"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
-const minimumTimeBetweenSessions = 30 * time.Minute
-const sessionDuration = 60 * time.Minute
+const slotInterval = 30 * time.Minute
 
 func GetUserAvailableWeekdays(w http.ResponseWriter, r *http.Request) {
 	queryParameters := r.URL.Query()
@@ -25,13 +29,18 @@ func GetUserAvailableWeekdays(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid id")
 		return
 	}
-	startDate, err := parseDateParameter(queryParameters.Get("from"))
+	location, err := parseTimeZoneParameter(queryParameters.Get("timeZone"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid time zone")
+		return
+	}
+	startDate, err := parseDateParameter(queryParameters.Get("from"), location)
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing date from")
 		return
 	}
 
-	endDate, err := parseDateParameter(queryParameters.Get("to"))
+	endDate, err := parseDateParameter(queryParameters.Get("to"), location)
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing date to")
 		return
@@ -43,43 +52,33 @@ func GetUserAvailableWeekdays(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if utils.IsEmptyStruct(user.Availability) {
+	if utils.IsEmptyStruct(user.Availability) && utils.IsEmptyStruct(user.AvailabilityExceptions) {
 		writeMessageResponse(w, r, http.StatusNotFound, "User does not have available timeslots")
 		return
 	}
 
-	result := calculateAvailableWeekdays(user.Availability, startDate, endDate)
+	result := calculateAvailableWeekdays(user, startDate, endDate)
 	writeJSONResponse(w, r, http.StatusOK, result)
 }
 
-func calculateAvailableWeekdays(availabilities []*model.Availability, startDate, endDate time.Time) []model.AvailableWeekday {
+// calculateAvailableWeekdays walks the days in the location of startDate and keeps those
+// that intersect at least one availability window in the mentor's own time zone.
+func calculateAvailableWeekdays(user *model.User, startDate, endDate time.Time) []model.AvailableWeekday {
 	var result []model.AvailableWeekday
 
-	uniqueWeekdays := getUniqueWeekdays(availabilities)
 	currentDate := startDate
 	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
-		if slices.Contains(uniqueWeekdays, currentDate.Weekday()) {
+		nextDate := currentDate.AddDate(0, 0, 1)
+		if len(getAvailabilityWindows(user, currentDate, nextDate)) > 0 {
 			result = append(result, model.AvailableWeekday{Date: currentDate, Weekday: currentDate.Weekday().String()})
 		}
 
-		currentDate = currentDate.Add(24 * time.Hour)
+		currentDate = nextDate
 	}
 
 	return result
 }
 
-func getUniqueWeekdays(availabilities []*model.Availability) []time.Weekday {
-	uniqueWeekdays := make(map[string]struct{})
-	for _, availability := range availabilities {
-		uniqueWeekdays[availability.Weekday] = struct{}{}
-	}
-	var result []time.Weekday
-	for weekday := range uniqueWeekdays {
-		result = append(result, utils.GetDayOfWeek(weekday))
-	}
-	return result
-}
-
 func GetUserAvailableSlots(w http.ResponseWriter, r *http.Request) {
 	queryParameters := r.URL.Query()
 	userId, err := primitive.ObjectIDFromHex(queryParameters.Get("id"))
@@ -87,35 +86,55 @@ func GetUserAvailableSlots(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid user id")
 		return
 	}
-	startDate, err := parseDateParameter(queryParameters.Get("date"))
+	location, err := parseTimeZoneParameter(queryParameters.Get("timeZone"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid time zone")
+		return
+	}
+	startDate, err := parseDateParameter(queryParameters.Get("date"), location)
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing date")
 		return
 	}
+	var sessionTypeId primitive.ObjectID
+	if queryParameters.Get("sessionTypeId") != "" {
+		sessionTypeId, err = primitive.ObjectIDFromHex(queryParameters.Get("sessionTypeId"))
+		if err != nil {
+			writeMessageResponse(w, r, http.StatusBadRequest, "Invalid session type id")
+			return
+		}
+	}
 	user, err := database.GetUserByID(userId)
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
 		return
 	}
-	bookedSessions, err := database.GetUserUpcomingSessions(user.Id, true) //todo in channel
+	sessionType, err := user.GetSessionType(sessionTypeId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusNotFound, "Session type not found")
+		return
+	}
+
+	endDate := startDate.AddDate(0, 0, 1)
+	bookedFrom, bookedTo := startDate, endDate.Add(sessionType.GetDuration())
+	if user.BookingRules.HasSessionLimits() {
+		// session limits are counted per week of the mentor, so the whole weeks are needed
+		location := utils.GetLocation(user.TimeZone)
+		bookedFrom = model.GetWeekRange(startDate, location).StartTime
+		bookedTo = model.GetWeekRange(bookedTo, location).EndTime
+	}
+	bookedSessions, err := database.GetMentorBookedSessions(user.Id, bookedFrom, bookedTo) //todo in channel
 	if err != nil {
 		writeMessageResponse(w, r, http.StatusInternalServerError, "Error user sessions info from database")
 		return
 	}
 
-	endDate := time.Date(
-		startDate.Year(),
-		startDate.Month(),
-		startDate.Day(),
-		23, 59, 0, 0,
-		startDate.Location(),
-	)
-	result := calculateAvailability(user.Availability, bookedSessions, startDate, endDate)
+	result := calculateAvailability(user, bookedSessions, sessionType, startDate, endDate)
 	writeJSONResponse(w, r, http.StatusOK, result)
 }
 
-func parseDateParameter(dateParam string) (time.Time, error) {
-	date, err := time.Parse(time.DateOnly, dateParam)
+func parseDateParameter(dateParam string, location *time.Location) (time.Time, error) {
+	date, err := time.ParseInLocation(time.DateOnly, dateParam, location)
 	if err != nil {
 		log.Printf("Error parsing date (%s): %v\n", dateParam, err)
 		return time.Time{}, err
@@ -123,44 +142,104 @@ func parseDateParameter(dateParam string) (time.Time, error) {
 	return date, nil
 }
 
-func calculateAvailability(availabilities []*model.Availability, bookedSessions []*model.SessionResponse, startDate, endDate time.Time) []model.TimeSlot {
+func parseTimeZoneParameter(timeZoneParam string) (*time.Location, error) {
+	if timeZoneParam == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timeZoneParam)
+	if err != nil {
+		log.Printf("Error parsing time zone (%s): %v\n", timeZoneParam, err)
+		return nil, err
+	}
+	return location, nil
+}
+
+// calculateAvailability returns free slots starting in [startDate, endDate). Slots are
+// reported in the location of startDate.
+func calculateAvailability(user *model.User, bookedSessions []*model.Session, sessionType *model.SessionType, startDate, endDate time.Time) []model.TimeSlot {
 	var result []model.TimeSlot
 
-	currentDate := startDate
-	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
-		for _, availability := range availabilities {
-			if currentDate.Weekday() == utils.GetDayOfWeek(availability.Weekday) {
-				slots := getSlots(availability, currentDate)
-				result = append(result, excludeBookedSlots(slots, bookedSessions)...)
+	for _, window := range getAvailabilityWindows(user, startDate, endDate) {
+		var slots []model.TimeSlot
+		for _, slot := range getSlots(window, sessionType) {
+			if slot.StartTime.Before(startDate) || !slot.StartTime.Before(endDate) {
+				continue
 			}
+			slots = append(slots, model.TimeSlot{StartTime: slot.StartTime.In(startDate.Location()), EndTime: slot.EndTime.In(startDate.Location())})
 		}
+		result = append(result, excludeBookedSlots(slots, bookedSessions, sessionType)...)
+	}
+	return applyBookingRules(result, user, bookedSessions, time.Now())
+}
 
-		currentDate = currentDate.Add(24 * time.Hour)
+// applyBookingRules drops slots the mentor's booking rules do not accept at the moment now.
+func applyBookingRules(slots []model.TimeSlot, user *model.User, bookedSessions []*model.Session, now time.Time) []model.TimeSlot {
+	var result []model.TimeSlot
+
+	location := utils.GetLocation(user.TimeZone)
+	for _, slot := range slots {
+		if user.BookingRules.CheckSessionTime(slot.StartTime, now) != nil {
+			continue
+		}
+		if user.BookingRules.CheckSessionLimits(bookedSessions, slot.StartTime, location) != nil {
+			continue
+		}
+		result = append(result, slot)
 	}
 	return result
 }
 
-func getSlots(availability *model.Availability, currentDate time.Time) []model.TimeSlot {
+// getAvailabilityWindows converts the weekly availability into concrete time ranges that
+// intersect [startDate, endDate) and applies the availability exceptions to them. Each
+// availability is evaluated on the calendar of its own time zone, so windows keep their
+// wall-clock time across DST changes.
+func getAvailabilityWindows(user *model.User, startDate, endDate time.Time) []model.TimeSlot {
 	var result []model.TimeSlot
 
-	availabilityStart, availabilityEnd := getAvailabilityTimeRange(availability, currentDate)
-
-	for current := availabilityStart; current.Before(availabilityEnd); current = current.Add(minimumTimeBetweenSessions) {
-		if current.Before(availabilityEnd.Add(sessionDuration)) {
-			result = append(result, model.TimeSlot{StartTime: current, EndTime: current.Add(sessionDuration)})
+	for _, availability := range user.Availability {
+		location := utils.GetLocation(availability.TimeZone)
+		firstDay := startDate.In(location).AddDate(0, 0, -1)
+		lastDay := endDate.In(location)
+		for currentDate := firstDay; !currentDate.After(lastDay); currentDate = currentDate.AddDate(0, 0, 1) {
+			if currentDate.Weekday() != utils.GetDayOfWeek(availability.Weekday) {
+				continue
+			}
+			availabilityStart, availabilityEnd := getAvailabilityTimeRange(availability, currentDate)
+			if availabilityStart.Before(endDate) && availabilityEnd.After(startDate) {
+				result = append(result, model.TimeSlot{StartTime: availabilityStart, EndTime: availabilityEnd})
+			}
 		}
 	}
+	return model.ApplyAvailabilityExceptions(result, user.AvailabilityExceptions, startDate, endDate)
+}
+
+// getSlots returns sessions of the given type that fit into the availability window.
+func getSlots(window model.TimeSlot, sessionType *model.SessionType) []model.TimeSlot {
+	var result []model.TimeSlot
+
+	duration := sessionType.GetDuration()
+	for current := window.StartTime; !current.Add(duration).After(window.EndTime); current = current.Add(slotInterval) {
+		result = append(result, model.TimeSlot{StartTime: current, EndTime: current.Add(duration)})
+	}
 	return result
 }
 
-func excludeBookedSlots(slots []model.TimeSlot, bookedSessions []*model.SessionResponse) []model.TimeSlot {
+// excludeBookedSlots drops slots that together with the buffers of their session type
+// overlap booked sessions together with the buffers those sessions were booked with.
+func excludeBookedSlots(slots []model.TimeSlot, bookedSessions []*model.Session, sessionType *model.SessionType) []model.TimeSlot {
 	var availableSlots []model.TimeSlot
 
 	for _, slot := range slots {
+		slotTimeRange := sessionType.GetBlockedTimeRange(slot.StartTime)
 		isBooked := false
-		for _, bookedSlot := range bookedSessions {
-			if bookedSlot.SessionTimeStart != nil && slot.EndTime.After(*bookedSlot.SessionTimeStart) && bookedSlot.SessionTimeEnd != nil && slot.StartTime.Before((*bookedSlot.SessionTimeEnd).Add(-1*time.Second)) && (bookedSlot.SessionStatus < 5) {
-				isBooked = true
+		for _, bookedSession := range bookedSessions {
+			for _, bookedTimeRange := range bookedSession.GetBlockedTimeRanges() {
+				if bookedTimeRange.Overlaps(slotTimeRange) {
+					isBooked = true
+					break
+				}
+			}
+			if isBooked {
 				break
 			}
 		}
@@ -173,9 +252,14 @@ func excludeBookedSlots(slots []model.TimeSlot, bookedSessions []*model.SessionR
 	return availableSlots
 }
 
+// getAvailabilityTimeRange builds the window on currentDate in the location of currentDate.
+// A window whose end is not after its start runs over midnight.
 func getAvailabilityTimeRange(availability *model.Availability, currentDate time.Time) (time.Time, time.Time) {
 	startTime := parseTime(availability.TimeFrom, currentDate)
 	endTime := parseTime(availability.TimeTo, currentDate)
+	if !endTime.After(startTime) {
+		endTime = parseTime(availability.TimeTo, currentDate.AddDate(0, 0, 1))
+	}
 	return startTime, endTime
 }
 
@@ -205,44 +289,79 @@ func CreateSession(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from session create request")
 		return
 	}
-	err = setSessionDetails(&mentorSession)
+	if mentorSession.SessionTimeStart == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Session time start wasn't provided")
+		return
+	}
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	if !mentorSession.MenteeId.IsZero() && mentorSession.MenteeId != userSession.UserId {
+		writeMessageResponse(w, r, http.StatusForbidden, "Sessions can be booked for yourself only")
+		return
+	}
+	mentorSession.MenteeId = userSession.UserId
+	mentee, err := database.GetUserByID(mentorSession.MenteeId)
 	if err != nil {
+		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	if !mentee.IsEmailVerified() {
+		writeMessageResponse(w, r, http.StatusForbidden, "Verify your email to book sessions")
+		return
+	}
+	mentor, err := setSessionDetails(&mentorSession)
+	if errors.Is(err, utils.SessionTypeNotFound) {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
 		writeMessageResponse(w, r, http.StatusNotFound, "Mentor was not found in database: "+err.Error())
 		return
 	}
-	updatedSession, err := database.CreateSession(mentorSession)
+	if err = mentor.BookingRules.CheckSessionTime(*mentorSession.SessionTimeStart, time.Now()); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	updatedSession, err := database.CreateSession(mentorSession, mentor)
 	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Database session insert error: "+err.Error())
+		writeSessionUpdateError(w, r, err, "Database session insert error: "+err.Error())
 		return
 	}
 	go emailNotifications.SendSessionWasCreatedEmail(updatedSession)
+	go inAppNotifications.NotifySessionCreated(updatedSession)
+	go webhooks.EmitSessionCreated(updatedSession)
 	writeJSONResponse(w, r, http.StatusCreated, updatedSession)
 }
 
-func setSessionDetails(session *model.Session) error {
+func setSessionDetails(session *model.Session) (*model.User, error) {
 	mentor, err := database.GetUserByID(session.MentorId)
 	if err != nil {
 		log.Printf("CancelSession: Failed to find user(%s) err: %v\n", session.MentorId.Hex(), err)
-		return err
+		return nil, err
 	}
-	session.MeetingLink = mentor.MeetingLink
-	if mentor.Prices != nil {
-		session.PaymentDetails = mentor.Prices[0].Price
-	} else {
-		session.PaymentDetails = "free"
+	sessionType, err := mentor.GetSessionType(session.SessionTypeId)
+	if err != nil {
+		return nil, err
 	}
+	session.MeetingLink = mentor.MeetingLink
+	session.SetSessionType(sessionType)
 	session.SessionStatus = model.PendingByMentor
-	sessionTimeEnd := (*session.SessionTimeStart).Add(60 * time.Minute)
+	sessionTimeEnd := (*session.SessionTimeStart).Add(sessionType.GetDuration())
 	session.SessionTimeEnd = &sessionTimeEnd
-	return nil
+	return mentor, nil
 }
 
 func GetSession(w http.ResponseWriter, r *http.Request) {
 	queryParameters := r.URL.Query()
-	id := queryParameters.Get("id")
-	mentorSession, err := database.GetSession(id)
+	session, _, ok := loadSessionForParticipant(w, r, queryParameters.Get("id"))
+	if !ok {
+		return
+	}
+	mentorSession, err := database.PrepareSessionResponse(session)
 	if err != nil {
-		writeMessageResponse(w, r, http.StatusNotFound, "Session not found")
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting session participants info")
 		return
 	}
 	writeJSONResponse(w, r, http.StatusCreated, mentorSession)
@@ -285,12 +404,6 @@ func groupSessionsByStatus(sessions []*model.SessionResponse) model.GroupedSessi
 }
 
 func RescheduleRequest(w http.ResponseWriter, r *http.Request) {
-	userSession := getUserSessionFromRequest(r)
-	if userSession == nil {
-		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
-		return
-	}
-
 	var mentorSession model.Session
 	err := parseJSONRequest(r, &mentorSession)
 	if err != nil {
@@ -301,31 +414,32 @@ func RescheduleRequest(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid json request")
 		return
 	}
-	user, err := database.GetUserByID(userSession.UserId)
+	session, role, ok := loadSessionForParticipant(w, r, mentorSession.SessionId.Hex())
+	if !ok {
+		return
+	}
+	mentor, err := database.GetUserByID(session.MentorId)
 	if err != nil {
-		writeMessageResponse(w, r, http.StatusNotFound, "Failed to find user(%s)")
+		writeMessageResponse(w, r, http.StatusNotFound, "Mentor was not found in database")
 		return
 	}
-	sessionTimeEnd := (*mentorSession.NewSessionTimeStart).Add(60 * time.Minute)
-	mentorSession.NewSessionTimeEnd = &sessionTimeEnd
-	setRescheduleStatus(&mentorSession, user.AsMentor)
-	updatedSession, err := database.RescheduleSession(mentorSession)
+	if err = mentor.BookingRules.CheckSessionTime(*mentorSession.NewSessionTimeStart, time.Now()); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	sessionTimeEnd := (*mentorSession.NewSessionTimeStart).Add(session.GetDuration())
+	updatedSession, err := database.RescheduleSession(session, role, mentor, mentorSession.NewSessionTimeStart, &sessionTimeEnd)
 	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error during session update")
+		writeSessionUpdateError(w, r, err, "Database error during session update")
+		return
 	}
 
 	go emailNotifications.SendSessionRescheduledEmail(updatedSession)
+	go inAppNotifications.NotifySessionRescheduled(updatedSession, role)
+	go webhooks.EmitSessionRescheduled(updatedSession)
 	writeJSONResponse(w, r, http.StatusOK, updatedSession)
 }
 
-func setRescheduleStatus(session *model.Session, isMentor bool) {
-	if isMentor {
-		session.SessionStatus = model.ReschedulingByMentor
-	} else {
-		session.SessionStatus = model.ReschedulingByMentee
-	}
-}
-
 func ConfirmSessionRequest(w http.ResponseWriter, r *http.Request) {
 	queryParameters := r.URL.Query()
 	sessionId := queryParameters.Get("sessionId")
@@ -333,32 +447,60 @@ func ConfirmSessionRequest(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, r, http.StatusBadRequest, "Session id wasn't provided")
 		return
 	}
-	updatedSession, err := database.ConfirmSession(sessionId)
+	session, role, ok := loadSessionForParticipant(w, r, sessionId)
+	if !ok {
+		return
+	}
+	updatedSession, err := database.ConfirmSession(session, role)
 	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error during session confirm")
+		writeSessionUpdateError(w, r, err, "Database error during session confirm")
 		return
 	}
 	go emailNotifications.SendSessionConfirmedEmail(updatedSession)
+	go inAppNotifications.NotifySessionConfirmed(updatedSession, role)
+	go webhooks.EmitSessionConfirmed(updatedSession)
 	writeJSONResponse(w, r, http.StatusOK, updatedSession)
 }
 
 func CancelRescheduleRequest(w http.ResponseWriter, r *http.Request) {
-	userSession := getUserSessionFromRequest(r)
-	if userSession == nil {
-		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+	queryParameters := r.URL.Query()
+	var cancellation model.SessionCancellation
+	if err := parseJSONRequest(r, &cancellation); err != nil && err != io.EOF {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from session cancel request")
 		return
 	}
-	queryParameters := r.URL.Query()
-	sessionId := queryParameters.Get("sessionId")
-	sessionIdObj, err := primitive.ObjectIDFromHex(sessionId)
-	if err != nil {
-		writeMessageResponse(w, r, http.StatusBadRequest, "Session id invalid")
+	cancellation.Reason = strings.TrimSpace(cancellation.Reason)
+	if utf8.RuneCountInString(cancellation.Reason) > model.MaxCancellationReasonLength {
+		writeMessageResponse(w, r, http.StatusBadRequest, utils.CancellationReasonTooLong.Error())
 		return
 	}
-	updateSession, err := database.CancelSession(sessionIdObj, userSession.UserId)
+	session, role, ok := loadSessionForParticipant(w, r, queryParameters.Get("sessionId"))
+	if !ok {
+		return
+	}
+	updateSession, err := database.CancelSession(session, role, &cancellation)
 	if err != nil {
-		writeMessageResponse(w, r, http.StatusInternalServerError, "Database error during session cancel")
+		writeSessionUpdateError(w, r, err, "Database error during session cancel")
 		return
 	}
+	go emailNotifications.SendSessionCanceledEmail(updateSession)
+	go inAppNotifications.NotifySessionCanceled(updateSession)
+	go webhooks.EmitSessionCancelled(updateSession)
 	writeJSONResponse(w, r, http.StatusOK, updateSession)
 }
+
+func writeSessionUpdateError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		writeMessageResponse(w, r, http.StatusNotFound, "Session not found")
+	case errors.Is(err, utils.SessionTransitionForbidden):
+		writeMessageResponse(w, r, http.StatusForbidden, err.Error())
+	case errors.Is(err, utils.SessionTransitionNotAllowed), errors.Is(err, utils.SessionStatusChanged),
+		errors.Is(err, utils.SessionSlotIsTaken), errors.Is(err, utils.MentorBookingInProgress),
+		errors.Is(err, utils.MentorDailyLimitReached), errors.Is(err, utils.MentorWeeklyLimitReached),
+		errors.Is(err, utils.MenteePendingLimitReached):
+		writeMessageResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		writeMessageResponse(w, r, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,246 @@
+package httpHandlers
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/model"
+	"oysterProject/utils"
+	"time"
+)
+
+const (
+	twoFactorIssuer        = "Oyster"
+	twoFactorChallengeSize = 32
+)
+
+// EnrollTwoFactor creates a TOTP secret for the user. 2FA is enforced after the first code is
+// confirmed with ConfirmTwoFactor.
+func EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	user, err := database.GetUserByID(userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error generating secret")
+		return
+	}
+	err = database.StartTwoFactorEnrollment(user.Id, secret)
+	if errors.Is(err, utils.TwoFactorAlreadyEnabled) {
+		writeMessageResponse(w, r, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, model.TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: utils.GetOTPAuthURI(twoFactorIssuer, user.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor enables 2FA with the first code from the authenticator app and returns the
+// recovery codes. They are not shown again.
+func ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	var request model.TwoFactorCode
+	if err := parseJSONRequest(r, &request); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	user, err := database.GetUserByID(userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	if user.TwoFactor == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Two-factor enrollment was not started")
+		return
+	}
+	if user.TwoFactor.Enabled {
+		writeMessageResponse(w, r, http.StatusConflict, utils.TwoFactorAlreadyEnabled.Error())
+		return
+	}
+	step, ok := utils.ValidateTOTP(user.TwoFactor.Secret, request.Code, time.Now())
+	if !ok {
+		writeMessageResponse(w, r, http.StatusBadRequest, utils.InvalidTwoFactorCode.Error())
+		return
+	}
+	recoveryCodes := model.RecoveryCodes{}
+	var recoveryCodeHashes []string
+	for i := 0; i < model.RecoveryCodesCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Error generating recovery codes")
+			return
+		}
+		recoveryCodes.RecoveryCodes = append(recoveryCodes.RecoveryCodes, code)
+		recoveryCodeHashes = append(recoveryCodeHashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+	err = database.EnableTwoFactor(user.Id, step, recoveryCodeHashes)
+	if errors.Is(err, utils.TwoFactorAlreadyEnabled) {
+		writeMessageResponse(w, r, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, recoveryCodes)
+}
+
+// DisableTwoFactor turns 2FA off, it needs a current code or a recovery code.
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userSession := getUserSessionFromRequest(r)
+	if userSession == nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "No user session info was found")
+		return
+	}
+	var request model.TwoFactorCode
+	if err := parseJSONRequest(r, &request); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	user, err := database.GetUserByID(userSession.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	if !user.IsTwoFactorEnabled() {
+		writeMessageResponse(w, r, http.StatusBadRequest, utils.TwoFactorNotEnabled.Error())
+		return
+	}
+	if err = verifySecondFactor(user, request.Code); errors.Is(err, utils.InvalidTwoFactorCode) {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	} else if errors.Is(err, utils.TwoFactorLocked) {
+		writeMessageResponse(w, r, http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error checking two-factor code")
+		return
+	}
+	if err = database.DisableTwoFactor(user.Id); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating user to MongoDB")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Two-factor authentication disabled")
+}
+
+// SignInTwoFactor finishes the sign in of a user with 2FA. The challenge is accepted a few
+// times only, so codes cannot be guessed.
+func SignInTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var request model.TwoFactorSignIn
+	if err := parseJSONRequest(r, &request); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	challengeHash := utils.HashToken(request.Challenge)
+	challenge, err := database.ClaimTwoFactorAttempt(challengeHash, time.Now().UTC())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusUnauthorized, "Sign in again, the challenge is invalid or expired")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error reading challenge from database")
+		return
+	}
+	user, err := database.GetUserByID(challenge.UserId)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusUnauthorized, "User unauthorized")
+		return
+	}
+	if user.IsTwoFactorEnabled() {
+		if err = verifySecondFactor(user, request.Code); errors.Is(err, utils.InvalidTwoFactorCode) {
+			writeMessageResponse(w, r, http.StatusUnauthorized, err.Error())
+			return
+		} else if errors.Is(err, utils.TwoFactorLocked) {
+			writeMessageResponse(w, r, http.StatusTooManyRequests, err.Error())
+			return
+		} else if err != nil {
+			writeMessageResponse(w, r, http.StatusInternalServerError, "Error checking two-factor code")
+			return
+		}
+	}
+	if err = database.ConsumeTwoFactorChallenge(challengeHash); err != nil {
+		writeMessageResponse(w, r, http.StatusUnauthorized, "Sign in again, the challenge is invalid or expired")
+		return
+	}
+	writeNewAuthSession(w, r, user.Id, "Sign in successful")
+}
+
+func writeTwoFactorChallenge(w http.ResponseWriter, r *http.Request, user *model.User) {
+	token, err := utils.GenerateRandomToken(twoFactorChallengeSize)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error generating challenge")
+		return
+	}
+	challenge := &model.TwoFactorChallenge{
+		TokenHash: utils.HashToken(token),
+		UserId:    user.Id,
+		ExpiresAt: time.Now().UTC().Add(model.TwoFactorChallengeTTL),
+	}
+	if err = database.SaveTwoFactorChallenge(challenge); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Database saving challenge error")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, model.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		Challenge:         token,
+		ExpiresAt:         challenge.ExpiresAt,
+	})
+}
+
+// verifySecondFactor accepts a TOTP code which was not used before or an unused recovery code.
+// Invalid codes are counted per user and lock the second factor for a while.
+func verifySecondFactor(user *model.User, code string) error {
+	now := time.Now().UTC()
+	if user.IsTwoFactorLocked(now) {
+		return utils.TwoFactorLocked
+	}
+	err := checkSecondFactor(user, code, now)
+	if errors.Is(err, utils.InvalidTwoFactorCode) {
+		_ = database.RecordTwoFactorFailure(user.Id, now)
+		return err
+	} else if err != nil {
+		return err
+	}
+	if user.TwoFactor.FailedAttempts > 0 || user.TwoFactor.LockedUntil != nil {
+		_ = database.ResetTwoFactorFailures(user.Id)
+	}
+	return nil
+}
+
+func checkSecondFactor(user *model.User, code string, now time.Time) error {
+	if step, ok := utils.ValidateTOTP(user.TwoFactor.Secret, code, now); ok {
+		used, err := database.UseTOTPStep(user.Id, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			log.Printf("verifySecondFactor: code of user(%s) was already used\n", user.Id.Hex())
+			return utils.InvalidTwoFactorCode
+		}
+		return nil
+	}
+	used, err := database.UseRecoveryCode(user.Id, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return utils.InvalidTwoFactorCode
+	}
+	log.Printf("verifySecondFactor: user(%s) used a recovery code\n", user.Id.Hex())
+	return nil
+}
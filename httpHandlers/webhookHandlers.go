@@ -0,0 +1,137 @@
+package httpHandlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/model"
+	"oysterProject/utils"
+)
+
+const (
+	webhookSecretSize             = 32
+	defaultWebhookDeliveriesLimit = 100
+	maxWebhookDeliveriesLimit     = 1000
+)
+
+func GetWebhookSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := database.GetWebhookSubscriptions()
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting webhooks from database")
+		return
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	writeJSONResponse(w, r, http.StatusOK, subscriptions)
+}
+
+// CreateWebhookSubscription returns the signing secret, it is not shown again afterwards.
+func CreateWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	var subscription model.WebhookSubscription
+	if err := parseJSONRequest(r, &subscription); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if err := model.ValidateWebhookSubscription(&subscription); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	secret, err := utils.GenerateRandomToken(webhookSecretSize)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Failed to generate webhook secret")
+		return
+	}
+	subscription.Id = primitive.NilObjectID
+	subscription.Secret = secret
+	subscription.Active = utils.BoolPtr(true)
+	if err = database.CreateWebhookSubscription(&subscription); err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error saving webhook")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusCreated, subscription)
+}
+
+func UpdateWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+	var subscription model.WebhookSubscription
+	if err = parseJSONRequest(r, &subscription); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Error parsing JSON from request")
+		return
+	}
+	if err = model.ValidateWebhookSubscription(&subscription); err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	updatedSubscription, err := database.UpdateWebhookSubscription(subscriptionId, &subscription)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Webhook not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error updating webhook")
+		return
+	}
+	updatedSubscription.Secret = ""
+	writeJSONResponse(w, r, http.StatusOK, updatedSubscription)
+}
+
+func DeleteWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+	err = database.DeleteWebhookSubscription(subscriptionId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Webhook not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error deleting webhook")
+		return
+	}
+	writeMessageResponse(w, r, http.StatusOK, "Webhook deleted")
+}
+
+func GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	subscriptionId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+	limit, err := getLimitParameter(r, defaultWebhookDeliveriesLimit, maxWebhookDeliveriesLimit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+	deliveries, err := database.GetWebhookDeliveries(subscriptionId, limit)
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error getting webhook deliveries from database")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDelivery sends the payload of a past delivery again with a new delivery id.
+func ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryId, err := primitive.ObjectIDFromHex(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		writeMessageResponse(w, r, http.StatusBadRequest, "Invalid delivery id")
+		return
+	}
+	replay, err := database.ReplayWebhookDelivery(deliveryId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		writeMessageResponse(w, r, http.StatusNotFound, "Webhook delivery not found")
+		return
+	} else if err != nil {
+		writeMessageResponse(w, r, http.StatusInternalServerError, "Error replaying webhook delivery")
+		return
+	}
+	writeJSONResponse(w, r, http.StatusAccepted, replay)
+}
@@ -0,0 +1,97 @@
+package inAppNotifications
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"oysterProject/database"
+	"oysterProject/model"
+)
+
+// NotifySessionCreated tells the mentor about the new booking.
+func NotifySessionCreated(session *model.SessionResponse) {
+	notify(newSessionNotification(model.NotificationSessionCreated, session, session.Mentor, session.Mentee))
+}
+
+// NotifySessionConfirmed tells the participant who did not confirm.
+func NotifySessionConfirmed(session *model.SessionResponse, confirmedBy model.SessionRole) {
+	recipient, actor := getCounterpart(session, confirmedBy)
+	notify(newSessionNotification(model.NotificationSessionConfirmed, session, recipient, actor))
+}
+
+// NotifySessionRescheduled tells the participant who has to accept the new time.
+func NotifySessionRescheduled(session *model.SessionResponse, rescheduledBy model.SessionRole) {
+	recipient, actor := getCounterpart(session, rescheduledBy)
+	notify(newSessionNotification(model.NotificationSessionRescheduled, session, recipient, actor))
+}
+
+// NotifySessionCanceled tells the participant who did not cancel.
+func NotifySessionCanceled(session *model.SessionResponse) {
+	canceledBy := model.MenteeRole
+	if session.SessionStatus == model.CanceledByMentor {
+		canceledBy = model.MentorRole
+	}
+	recipient, actor := getCounterpart(session, canceledBy)
+	notify(newSessionNotification(model.NotificationSessionCanceled, session, recipient, actor))
+}
+
+// NotifyReviewCreated tells the mentor about the new review.
+func NotifyReviewCreated(review *model.Review) {
+	if review.MentorId.IsZero() {
+		return
+	}
+	notification := &model.Notification{
+		UserId:    review.MentorId,
+		Type:      model.NotificationReviewCreated,
+		SessionId: review.SessionId,
+		ReviewId:  review.ReviewId,
+	}
+	if !review.MenteeId.IsZero() {
+		notification.ActorId = review.MenteeId
+		if mentee, err := database.GetUserByID(review.MenteeId); err == nil {
+			notification.ActorName = mentee.Username
+		}
+	}
+	notify(notification)
+}
+
+// NotifyUserApproved is called by the approval job on every run until the approval email is
+// delivered, the dedupe key keeps a single notification.
+func NotifyUserApproved(user *model.User) {
+	notify(&model.Notification{
+		UserId:    user.Id,
+		Type:      model.NotificationUserApproved,
+		DedupeKey: string(model.NotificationUserApproved) + ":" + user.Id.Hex(),
+	})
+}
+
+func newSessionNotification(notificationType model.NotificationType, session *model.SessionResponse, recipient, actor *model.UserImage) *model.Notification {
+	return &model.Notification{
+		UserId:           recipient.UserId,
+		Type:             notificationType,
+		ActorId:          actor.UserId,
+		ActorName:        actor.Name,
+		SessionId:        session.SessionId,
+		SessionTimeStart: session.SessionTimeStart,
+	}
+}
+
+func getCounterpart(session *model.SessionResponse, role model.SessionRole) (recipient, actor *model.UserImage) {
+	if role == model.MentorRole {
+		return session.Mentee, session.Mentor
+	}
+	return session.Mentor, session.Mentee
+}
+
+// notify stores the notification unless the user turned off the in-app channel of its category.
+func notify(notification *model.Notification) {
+	if notification.UserId == primitive.NilObjectID {
+		return
+	}
+	preferences, err := database.GetNotificationPreferences(notification.UserId)
+	if err == nil && !preferences.IsEnabled(notification.Type.GetCategory(), model.ChannelInApp) {
+		return
+	}
+	if err = database.InsertNotification(notification); err != nil {
+		log.Printf("Failed to save %s notification for user(%s): %v\n", notification.Type, notification.UserId.Hex(), err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"oysterProject/emailNotifications"
 	"oysterProject/routes"
 	"oysterProject/schedulerJobs"
+	_ "time/tzdata"
 )
 
 func main() {
@@ -18,9 +19,15 @@ func main() {
 		log.Fatal(err)
 	}
 	defer database.CloseMongoDBConnection()
+	if err = database.MigrateOffsetTimeZones(); err != nil {
+		log.Fatal(err)
+	}
+	if err = database.EnsureIndexes(); err != nil {
+		log.Fatal(err)
+	}
 	database.ConnectToS3()
-	schedulerJobs.StartJobs()
 	emailNotifications.InitMailClient()
+	schedulerJobs.StartJobs()
 
 	r := chi.NewRouter()
 	routes.ConfigureCors(r)
@@ -10,10 +10,20 @@ type Oauth2User struct {
 	Email string `json:"email" bson:"email"`
 }
 
+// AuthSession is one signed in device. SessionId is the bearer token of the session and is
+// never serialized, the sessions list identifies sessions by PublicId. Sessions created before
+// the device details were recorded have no CreatedAt, LastSeenAt, IP and UserAgent until they
+// are used again.
 type AuthSession struct {
-	SessionId primitive.ObjectID `bson:"_id,omitempty"`
-	UserId    primitive.ObjectID `bson:"userId"`
-	Expiry    int64              `bson:"expiry"`
+	SessionId  primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	PublicId   string             `json:"id" bson:"publicId,omitempty"`
+	UserId     primitive.ObjectID `json:"-" bson:"userId"`
+	Expiry     int64              `json:"expiry" bson:"expiry"`
+	CreatedAt  *time.Time         `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	LastSeenAt *time.Time         `json:"lastSeenAt,omitempty" bson:"lastSeenAt,omitempty"`
+	IP         string             `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent  string             `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	Current    bool               `json:"current" bson:"-"`
 }
 
 func (s AuthSession) isExpired() bool {
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"oysterProject/utils"
+	"sort"
+	"time"
+)
+
+const maxAvailabilityExceptionDuration = 366 * 24 * time.Hour
+
+type AvailabilityExceptionType string
+
+const (
+	BlockedAvailability AvailabilityExceptionType = "blocked"
+	ExtraAvailability   AvailabilityExceptionType = "extra"
+)
+
+// AvailabilityException overrides the weekly availability for a date range: blocked ranges
+// remove time from it (vacations, days off) and extra ranges add one-off windows.
+type AvailabilityException struct {
+	Id       primitive.ObjectID        `json:"id" bson:"_id"`
+	Type     AvailabilityExceptionType `json:"type" bson:"type"`
+	TimeFrom time.Time                 `json:"timeFrom" bson:"timeFrom"`
+	TimeTo   time.Time                 `json:"timeTo" bson:"timeTo"`
+	Comment  string                    `json:"comment,omitempty" bson:"comment,omitempty"`
+}
+
+func ValidateAvailabilityException(exception *AvailabilityException) error {
+	if exception.Type != BlockedAvailability && exception.Type != ExtraAvailability {
+		return utils.InvalidAvailabilityException
+	}
+	if !exception.TimeTo.After(exception.TimeFrom) || exception.TimeTo.Sub(exception.TimeFrom) > maxAvailabilityExceptionDuration {
+		return utils.InvalidAvailabilityException
+	}
+	exception.TimeFrom = exception.TimeFrom.UTC()
+	exception.TimeTo = exception.TimeTo.UTC()
+	return nil
+}
+
+func (exception *AvailabilityException) GetTimeRange() TimeSlot {
+	return TimeSlot{StartTime: exception.TimeFrom, EndTime: exception.TimeTo}
+}
+
+// ApplyAvailabilityExceptions adds the extra windows intersecting [startDate, endDate) to the
+// weekly windows, merges overlapping windows and cuts the blocked ranges out of the result.
+func ApplyAvailabilityExceptions(windows []TimeSlot, exceptions []*AvailabilityException, startDate, endDate time.Time) []TimeSlot {
+	requestedRange := TimeSlot{StartTime: startDate, EndTime: endDate}
+	for _, exception := range exceptions {
+		if exception.Type == ExtraAvailability && exception.GetTimeRange().Overlaps(requestedRange) {
+			windows = append(windows, exception.GetTimeRange())
+		}
+	}
+	windows = mergeTimeRanges(windows)
+	for _, exception := range exceptions {
+		if exception.Type == BlockedAvailability {
+			windows = subtractTimeRange(windows, exception.GetTimeRange())
+		}
+	}
+	return windows
+}
+
+func mergeTimeRanges(timeRanges []TimeSlot) []TimeSlot {
+	sort.Slice(timeRanges, func(i, j int) bool {
+		return timeRanges[i].StartTime.Before(timeRanges[j].StartTime)
+	})
+	var result []TimeSlot
+	for _, timeRange := range timeRanges {
+		last := len(result) - 1
+		if last >= 0 && !timeRange.StartTime.After(result[last].EndTime) {
+			if timeRange.EndTime.After(result[last].EndTime) {
+				result[last].EndTime = timeRange.EndTime
+			}
+			continue
+		}
+		result = append(result, timeRange)
+	}
+	return result
+}
+
+func subtractTimeRange(timeRanges []TimeSlot, blocked TimeSlot) []TimeSlot {
+	var result []TimeSlot
+	for _, timeRange := range timeRanges {
+		if !timeRange.Overlaps(blocked) {
+			result = append(result, timeRange)
+			continue
+		}
+		if timeRange.StartTime.Before(blocked.StartTime) {
+			result = append(result, TimeSlot{StartTime: timeRange.StartTime, EndTime: blocked.StartTime})
+		}
+		if timeRange.EndTime.After(blocked.EndTime) {
+			result = append(result, TimeSlot{StartTime: blocked.EndTime, EndTime: timeRange.EndTime})
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"oysterProject/utils"
+	"time"
+)
+
+const MaxBookingDaysInAdvance = 365
+
+// BookingRules limit when and how often a mentor can be booked. A zero value disables the rule.
+type BookingRules struct {
+	MinNoticeMinutes    int `json:"minNoticeMinutes" bson:"minNoticeMinutes"`
+	MaxDaysInAdvance    int `json:"maxDaysInAdvance" bson:"maxDaysInAdvance"`
+	MaxSessionsPerDay   int `json:"maxSessionsPerDay" bson:"maxSessionsPerDay"`
+	MaxSessionsPerWeek  int `json:"maxSessionsPerWeek" bson:"maxSessionsPerWeek"`
+	MaxPendingPerMentee int `json:"maxPendingPerMentee" bson:"maxPendingPerMentee"`
+}
+
+func ValidateBookingRules(rules *BookingRules) error {
+	if rules.MinNoticeMinutes < 0 || rules.MaxSessionsPerDay < 0 || rules.MaxSessionsPerWeek < 0 ||
+		rules.MaxPendingPerMentee < 0 || rules.MaxDaysInAdvance < 0 || rules.MaxDaysInAdvance > MaxBookingDaysInAdvance {
+		return utils.InvalidBookingRules
+	}
+	if rules.MinNoticeMinutes > 0 && rules.MaxDaysInAdvance > 0 &&
+		time.Duration(rules.MinNoticeMinutes)*time.Minute >= time.Duration(rules.MaxDaysInAdvance)*24*time.Hour {
+		return utils.InvalidBookingRules
+	}
+	return nil
+}
+
+// CheckSessionTime validates minimum notice and booking horizon of a session starting at sessionTimeStart.
+func (rules *BookingRules) CheckSessionTime(sessionTimeStart, now time.Time) error {
+	if !sessionTimeStart.After(now) {
+		return utils.SessionTimeInPast
+	}
+	if rules == nil {
+		return nil
+	}
+	if sessionTimeStart.Before(now.Add(time.Duration(rules.MinNoticeMinutes) * time.Minute)) {
+		return utils.BookingNoticeTooShort
+	}
+	if rules.MaxDaysInAdvance > 0 && sessionTimeStart.After(now.AddDate(0, 0, rules.MaxDaysInAdvance)) {
+		return utils.BookingTooFarInAdvance
+	}
+	return nil
+}
+
+// CheckSessionLimits validates the daily and weekly caps for a session starting at sessionTimeStart.
+// Days and weeks are counted on the calendar of the mentor's time zone, bookedSessions must not
+// contain the session being checked.
+func (rules *BookingRules) CheckSessionLimits(bookedSessions []*Session, sessionTimeStart time.Time, location *time.Location) error {
+	if rules == nil {
+		return nil
+	}
+	day, week := GetDayRange(sessionTimeStart, location), GetWeekRange(sessionTimeStart, location)
+	sessionsPerDay, sessionsPerWeek := 0, 0
+	for _, bookedSession := range bookedSessions {
+		if bookedSession.StartsWithin(day) {
+			sessionsPerDay++
+		}
+		if bookedSession.StartsWithin(week) {
+			sessionsPerWeek++
+		}
+	}
+	if rules.MaxSessionsPerDay > 0 && sessionsPerDay >= rules.MaxSessionsPerDay {
+		return utils.MentorDailyLimitReached
+	}
+	if rules.MaxSessionsPerWeek > 0 && sessionsPerWeek >= rules.MaxSessionsPerWeek {
+		return utils.MentorWeeklyLimitReached
+	}
+	return nil
+}
+
+func (rules *BookingRules) CheckPendingSessions(pendingSessionsCount int64) error {
+	if rules == nil || rules.MaxPendingPerMentee == 0 {
+		return nil
+	}
+	if pendingSessionsCount >= int64(rules.MaxPendingPerMentee) {
+		return utils.MenteePendingLimitReached
+	}
+	return nil
+}
+
+func (rules *BookingRules) HasSessionLimits() bool {
+	return rules != nil && (rules.MaxSessionsPerDay > 0 || rules.MaxSessionsPerWeek > 0)
+}
+
+func GetDayRange(t time.Time, location *time.Location) TimeSlot {
+	localTime := t.In(location)
+	dayStart := time.Date(localTime.Year(), localTime.Month(), localTime.Day(), 0, 0, 0, 0, location)
+	return TimeSlot{StartTime: dayStart, EndTime: dayStart.AddDate(0, 0, 1)}
+}
+
+// GetWeekRange returns the week from Monday to Sunday containing t.
+func GetWeekRange(t time.Time, location *time.Location) TimeSlot {
+	dayStart := GetDayRange(t, location).StartTime
+	weekStart := dayStart.AddDate(0, 0, -((int(dayStart.Weekday()) + 6) % 7))
+	return TimeSlot{StartTime: weekStart, EndTime: weekStart.AddDate(0, 0, 7)}
+}
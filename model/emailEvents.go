@@ -0,0 +1,56 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type EmailDeliverabilityStatus string
+
+const (
+	EmailBounced      EmailDeliverabilityStatus = "bounced"
+	EmailSpamReported EmailDeliverabilityStatus = "spamReported"
+
+	sendGridBounceEvent     = "bounce"
+	sendGridSpamReportEvent = "spamreport"
+	sendGridBlockedBounce   = "blocked"
+)
+
+// EmailDeliverability is set on the user when the address hard-bounced or the user reported
+// our email as spam. No emails are sent to such an address until the user changes it.
+type EmailDeliverability struct {
+	Status    EmailDeliverabilityStatus `json:"status" bson:"status"`
+	Reason    string                    `json:"reason,omitempty" bson:"reason,omitempty"`
+	UpdatedAt time.Time                 `json:"updatedAt" bson:"updatedAt"`
+}
+
+// SendGridEvent is one entry of the SendGrid event webhook. The outbox email id is sent to
+// SendGrid as a custom argument and comes back with every event of the email.
+type SendGridEvent struct {
+	EventId          string             `json:"sg_event_id" bson:"_id"`
+	MessageId        string             `json:"sg_message_id" bson:"messageId,omitempty"`
+	Email            string             `json:"email" bson:"email"`
+	Event            string             `json:"event" bson:"event"`
+	Type             string             `json:"type" bson:"type,omitempty"`
+	Reason           string             `json:"reason" bson:"reason,omitempty"`
+	Status           string             `json:"status" bson:"status,omitempty"`
+	Timestamp        int64              `json:"timestamp" bson:"timestamp"`
+	OutboxEmailIdHex string             `json:"outboxEmailId" bson:"-"`
+	OutboxEmailId    primitive.ObjectID `json:"-" bson:"outboxEmailId,omitempty"`
+	ReceivedAt       time.Time          `json:"-" bson:"receivedAt"`
+}
+
+// GetDeliverability returns the status the event puts the address in, or nil for events like
+// opens and blocked (soft) bounces.
+func (event *SendGridEvent) GetDeliverability() *EmailDeliverability {
+	var status EmailDeliverabilityStatus
+	switch {
+	case event.Event == sendGridBounceEvent && event.Type != sendGridBlockedBounce:
+		status = EmailBounced
+	case event.Event == sendGridSpamReportEvent:
+		status = EmailSpamReported
+	default:
+		return nil
+	}
+	return &EmailDeliverability{Status: status, Reason: event.Reason, UpdatedAt: time.Unix(event.Timestamp, 0).UTC()}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type EmailStatus string
+
+const (
+	EmailQueued  EmailStatus = "queued"
+	EmailSending EmailStatus = "sending"
+	EmailSent    EmailStatus = "sent"
+	EmailFailed  EmailStatus = "failed"
+	EmailDead    EmailStatus = "dead"
+
+	MaxEmailAttempts = 8
+	// AccountEmailRetention limits how long account emails, which carry sign-in links, are kept.
+	AccountEmailRetention = 7 * 24 * time.Hour
+)
+
+type EmailAttachment struct {
+	Filename    string `bson:"filename"`
+	ContentType string `bson:"contentType"`
+	Content     []byte `bson:"content"`
+}
+
+// EmailSentUpdate sets Field of the document to true once every email of the group is sent,
+// e.g. emailWasSent of a session after both review requests were delivered.
+type EmailSentUpdate struct {
+	Collection string             `bson:"collection"`
+	DocumentId primitive.ObjectID `bson:"documentId"`
+	Field      string             `bson:"field"`
+}
+
+// OutboxEmail is a rendered email waiting for delivery or kept as the delivery log.
+// Emails with a DedupeKey are enqueued only once.
+type OutboxEmail struct {
+	Id            primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	UserId        primitive.ObjectID   `json:"userId,omitempty" bson:"userId,omitempty"`
+	ToName        string               `json:"toName" bson:"toName"`
+	ToEmail       string               `json:"toEmail" bson:"toEmail"`
+	Template      string               `json:"template" bson:"template"`
+	Category      NotificationCategory `json:"category" bson:"category"`
+	Language      string               `json:"language,omitempty" bson:"language,omitempty"`
+	Subject       string               `json:"subject" bson:"subject"`
+	Text          string               `json:"-" bson:"text"`
+	HTML          string               `json:"-" bson:"html"`
+	Headers       map[string]string    `json:"-" bson:"headers,omitempty"`
+	Attachments   []EmailAttachment    `json:"-" bson:"attachments,omitempty"`
+	DedupeKey     string               `json:"-" bson:"dedupeKey,omitempty"`
+	Group         string               `json:"-" bson:"group,omitempty"`
+	OnSent        *EmailSentUpdate     `json:"-" bson:"onSent,omitempty"`
+	Status        EmailStatus          `json:"status" bson:"status"`
+	Attempts      int                  `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time            `json:"nextAttemptAt" bson:"nextAttemptAt"`
+	LastError     string               `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	ClaimedBy     string               `json:"-" bson:"claimedBy,omitempty"`
+	ClaimedUntil  *time.Time           `json:"-" bson:"claimedUntil,omitempty"`
+	CreatedAt     time.Time            `json:"createdAt" bson:"createdAt"`
+	SentAt        *time.Time           `json:"sentAt,omitempty" bson:"sentAt,omitempty"`
+	LastEvent     string               `json:"lastEvent,omitempty" bson:"lastEvent,omitempty"`
+	LastEventAt   *time.Time           `json:"lastEventAt,omitempty" bson:"lastEventAt,omitempty"`
+	ExpiresAt     *time.Time           `json:"-" bson:"expiresAt,omitempty"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// JobLock is the lease a scheduler job instance holds while running. The lease expires
+// unless the owner renews it, so a crashed instance does not block the job.
+type JobLock struct {
+	JobName        string     `json:"jobName" bson:"_id"`
+	Owner          string     `json:"owner" bson:"owner"`
+	AcquiredAt     time.Time  `json:"acquiredAt" bson:"acquiredAt"`
+	ExpiresAt      time.Time  `json:"expiresAt" bson:"expiresAt"`
+	LastFinishedAt *time.Time `json:"lastFinishedAt,omitempty" bson:"lastFinishedAt,omitempty"`
+}
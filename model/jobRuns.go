@@ -0,0 +1,46 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunFailed    JobRunStatus = "failed"
+
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+type JobRun struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	JobName    string             `json:"jobName" bson:"jobName"`
+	Instance   string             `json:"instance" bson:"instance"`
+	Trigger    string             `json:"trigger" bson:"trigger"`
+	Status     JobRunStatus       `json:"status" bson:"status"`
+	StartedAt  time.Time          `json:"startedAt" bson:"startedAt"`
+	FinishedAt *time.Time         `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+	DurationMs int64              `json:"durationMs" bson:"durationMs"`
+	Counts     map[string]int64   `json:"counts,omitempty" bson:"counts,omitempty"`
+	Error      string             `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+type JobInfo struct {
+	Name     string    `json:"name"`
+	Interval string    `json:"interval"`
+	NextRun  time.Time `json:"nextRun"`
+	LastRun  *JobRun   `json:"lastRun,omitempty"`
+	Lock     *JobLock  `json:"lock,omitempty"`
+	Running  bool      `json:"running"`
+}
+
+func (run *JobRun) AddCount(name string, count int64) {
+	if run.Counts == nil {
+		run.Counts = make(map[string]int64)
+	}
+	run.Counts[name] += count
+}
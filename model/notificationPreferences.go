@@ -0,0 +1,91 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"oysterProject/utils"
+	"time"
+)
+
+type NotificationCategory string
+
+type NotificationChannel string
+
+const (
+	CategoryOnboarding     NotificationCategory = "onboarding"
+	CategorySessionUpdates NotificationCategory = "sessionUpdates"
+	CategoryReminders      NotificationCategory = "reminders"
+	CategoryReviewRequests NotificationCategory = "reviewRequests"
+	CategoryReviews        NotificationCategory = "reviews"
+	// CategoryAccount covers security emails like password resets, users cannot opt out of it.
+	CategoryAccount NotificationCategory = "account"
+
+	ChannelEmail NotificationChannel = "email"
+	ChannelInApp NotificationChannel = "inApp"
+)
+
+var NotificationCategories = []NotificationCategory{CategoryOnboarding, CategorySessionUpdates, CategoryReminders, CategoryReviewRequests, CategoryReviews}
+
+var NotificationChannels = []NotificationChannel{ChannelEmail, ChannelInApp}
+
+// NotificationPreferences stores the choices of the user, categories and channels which are
+// missing are enabled.
+type NotificationPreferences struct {
+	UserId     primitive.ObjectID                                    `json:"-" bson:"_id"`
+	Categories map[NotificationCategory]map[NotificationChannel]bool `json:"categories" bson:"categories"`
+	UpdatedAt  *time.Time                                            `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+func (preferences *NotificationPreferences) IsEnabled(category NotificationCategory, channel NotificationChannel) bool {
+	if preferences == nil {
+		return true
+	}
+	enabled, ok := preferences.Categories[category][channel]
+	return !ok || enabled
+}
+
+// WithDefaults returns the preferences with every category and channel filled in.
+func (preferences *NotificationPreferences) WithDefaults() *NotificationPreferences {
+	result := &NotificationPreferences{Categories: make(map[NotificationCategory]map[NotificationChannel]bool)}
+	if preferences != nil {
+		result.UserId, result.UpdatedAt = preferences.UserId, preferences.UpdatedAt
+	}
+	for _, category := range NotificationCategories {
+		result.Categories[category] = make(map[NotificationChannel]bool)
+		for _, channel := range NotificationChannels {
+			result.Categories[category][channel] = preferences.IsEnabled(category, channel)
+		}
+	}
+	return result
+}
+
+func ValidateNotificationPreferences(preferences *NotificationPreferences) error {
+	for category, channels := range preferences.Categories {
+		if !isNotificationCategory(category) {
+			return utils.InvalidNotificationPreferences
+		}
+		for channel := range channels {
+			if !isNotificationChannel(channel) {
+				return utils.InvalidNotificationPreferences
+			}
+		}
+	}
+	return nil
+}
+
+func isNotificationCategory(category NotificationCategory) bool {
+	for _, c := range NotificationCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotificationChannel(channel NotificationChannel) bool {
+	for _, c := range NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type NotificationType string
+
+const (
+	NotificationSessionCreated     NotificationType = "sessionCreated"
+	NotificationSessionConfirmed   NotificationType = "sessionConfirmed"
+	NotificationSessionRescheduled NotificationType = "sessionRescheduled"
+	NotificationSessionCanceled    NotificationType = "sessionCanceled"
+	NotificationReviewCreated      NotificationType = "reviewCreated"
+	NotificationUserApproved       NotificationType = "userApproved"
+)
+
+// Notification is shown in the notification center of the user. The frontend renders the
+// text from the type, the actor and the session.
+type Notification struct {
+	Id               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserId           primitive.ObjectID `json:"-" bson:"userId"`
+	Type             NotificationType   `json:"type" bson:"type"`
+	ActorId          primitive.ObjectID `json:"actorId,omitempty" bson:"actorId,omitempty"`
+	ActorName        string             `json:"actorName,omitempty" bson:"actorName,omitempty"`
+	SessionId        primitive.ObjectID `json:"sessionId,omitempty" bson:"sessionId,omitempty"`
+	SessionTimeStart *time.Time         `json:"sessionTimeStart,omitempty" bson:"sessionTimeStart,omitempty"`
+	ReviewId         primitive.ObjectID `json:"reviewId,omitempty" bson:"reviewId,omitempty"`
+	DedupeKey        string             `json:"-" bson:"dedupeKey,omitempty"`
+	Read             bool               `json:"read" bson:"read"`
+	ReadAt           *time.Time         `json:"readAt,omitempty" bson:"readAt,omitempty"`
+	CreatedAt        time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type UnreadNotifications struct {
+	Count int64 `json:"count"`
+}
+
+func (notificationType NotificationType) GetCategory() NotificationCategory {
+	switch notificationType {
+	case NotificationReviewCreated:
+		return CategoryReviews
+	case NotificationUserApproved:
+		return CategoryOnboarding
+	default:
+		return CategorySessionUpdates
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const PasswordResetTokenTTL = time.Hour
+
+// PasswordResetToken is stored under the hash of the token sent to the user, the token itself
+// is never saved. Expired tokens are removed by a TTL index on ExpiresAt.
+type PasswordResetToken struct {
+	TokenHash string             `bson:"_id"`
+	UserId    primitive.ObjectID `bson:"userId"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+type PasswordReset struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
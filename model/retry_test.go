@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Minute},
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 3, want: 4 * time.Minute},
+		{attempts: 8, want: 128 * time.Minute},
+		{attempts: 9, want: 256 * time.Minute},
+		{attempts: 10, want: 6 * time.Hour},
+		{attempts: 100, want: 6 * time.Hour},
+	}
+	for _, test := range tests {
+		if got := GetRetryDelay(test.attempts); got != test.want {
+			t.Errorf("GetRetryDelay(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderPending    ReminderStatus = "pending"
+	ReminderProcessing ReminderStatus = "processing"
+	ReminderSent       ReminderStatus = "sent"
+	ReminderSkipped    ReminderStatus = "skipped"
+	ReminderFailed     ReminderStatus = "failed"
+
+	MaxReminderAttempts = 5
+)
+
+// SessionReminderOffsets lists how long before the session start reminders are sent.
+var SessionReminderOffsets = []time.Duration{24 * time.Hour, 30 * time.Minute}
+
+// SessionReminder is created for every offset of a confirmed session. Reminders are keyed by
+// the session start, so a rescheduled session gets new reminders and old ones are skipped.
+type SessionReminder struct {
+	Id               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SessionId        primitive.ObjectID `json:"sessionId" bson:"sessionId"`
+	OffsetMinutes    int                `json:"offsetMinutes" bson:"offsetMinutes"`
+	SessionTimeStart time.Time          `json:"sessionTimeStart" bson:"sessionTimeStart"`
+	SendAt           time.Time          `json:"sendAt" bson:"sendAt"`
+	Status           ReminderStatus     `json:"status" bson:"status"`
+	Attempts         int                `json:"attempts" bson:"attempts"`
+	ClaimedBy        string             `json:"claimedBy,omitempty" bson:"claimedBy,omitempty"`
+	ClaimedUntil     *time.Time         `json:"claimedUntil,omitempty" bson:"claimedUntil,omitempty"`
+	SentAt           *time.Time         `json:"sentAt,omitempty" bson:"sentAt,omitempty"`
+	CreatedAt        time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+func (reminder *SessionReminder) GetOffset() time.Duration {
+	return time.Duration(reminder.OffsetMinutes) * time.Minute
+}
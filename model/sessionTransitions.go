@@ -0,0 +1,104 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"oysterProject/utils"
+)
+
+type SessionRole int
+
+const (
+	MentorRole SessionRole = iota
+	MenteeRole
+)
+
+// sessionTransitions lists for every status the statuses it may move to and the
+// participants allowed to make that move. Statuses missing as keys are final.
+// Completed and Expired are set by the status calculation job only.
+var sessionTransitions = map[Status]map[Status][]SessionRole{
+	CreatedByMentee: {
+		PendingByMentor:  {MenteeRole},
+		CanceledByMentor: {MentorRole},
+		CanceledByMentee: {MenteeRole},
+	},
+	PendingByMentor: {
+		Confirmed:            {MentorRole},
+		ReschedulingByMentor: {MentorRole},
+		ReschedulingByMentee: {MenteeRole},
+		CanceledByMentor:     {MentorRole},
+		CanceledByMentee:     {MenteeRole},
+	},
+	ReschedulingByMentor: {
+		Confirmed:            {MenteeRole},
+		ReschedulingByMentor: {MentorRole},
+		ReschedulingByMentee: {MenteeRole},
+		CanceledByMentor:     {MentorRole},
+		CanceledByMentee:     {MenteeRole},
+	},
+	ReschedulingByMentee: {
+		Confirmed:            {MentorRole},
+		ReschedulingByMentor: {MentorRole},
+		ReschedulingByMentee: {MenteeRole},
+		CanceledByMentor:     {MentorRole},
+		CanceledByMentee:     {MenteeRole},
+	},
+	Confirmed: {
+		ReschedulingByMentor: {MentorRole},
+		ReschedulingByMentee: {MenteeRole},
+		CanceledByMentor:     {MentorRole},
+		CanceledByMentee:     {MenteeRole},
+	},
+}
+
+func (r SessionRole) String() string {
+	switch r {
+	case MentorRole:
+		return "mentor"
+	case MenteeRole:
+		return "mentee"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Status) CanTransitionTo(newStatus Status, role SessionRole) error {
+	allowedTransitions, ok := sessionTransitions[s]
+	if !ok {
+		return utils.SessionTransitionNotAllowed
+	}
+	allowedRoles, ok := allowedTransitions[newStatus]
+	if !ok {
+		return utils.SessionTransitionNotAllowed
+	}
+	for _, allowedRole := range allowedRoles {
+		if allowedRole == role {
+			return nil
+		}
+	}
+	return utils.SessionTransitionForbidden
+}
+
+func (session *Session) GetParticipantRole(userId primitive.ObjectID) (SessionRole, bool) {
+	switch userId {
+	case session.MentorId:
+		return MentorRole, true
+	case session.MenteeId:
+		return MenteeRole, true
+	default:
+		return 0, false
+	}
+}
+
+func GetRescheduleStatus(role SessionRole) Status {
+	if role == MentorRole {
+		return ReschedulingByMentor
+	}
+	return ReschedulingByMentee
+}
+
+func GetCancelStatus(role SessionRole) Status {
+	if role == MentorRole {
+		return CanceledByMentor
+	}
+	return CanceledByMentee
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"oysterProject/utils"
+	"time"
+)
+
+const (
+	DefaultSessionDurationMinutes = 60
+	MinSessionDurationMinutes     = 15
+	MaxSessionDurationMinutes     = 240
+	MaxSessionBufferMinutes       = 120
+)
+
+type SessionType struct {
+	Id                  primitive.ObjectID `json:"id" bson:"_id"`
+	Name                string             `json:"name" bson:"name"`
+	DurationMinutes     int                `json:"durationMinutes" bson:"durationMinutes"`
+	BufferBeforeMinutes int                `json:"bufferBeforeMinutes" bson:"bufferBeforeMinutes"`
+	BufferAfterMinutes  int                `json:"bufferAfterMinutes" bson:"bufferAfterMinutes"`
+	Price               string             `json:"price" bson:"price"`
+}
+
+// GetSessionType finds the session type offered by the mentor. An empty id selects the first
+// configured type, mentors without session types offer the default 60 minutes session.
+func (user *User) GetSessionType(sessionTypeId primitive.ObjectID) (*SessionType, error) {
+	if sessionTypeId.IsZero() {
+		if len(user.SessionTypes) > 0 {
+			return user.SessionTypes[0], nil
+		}
+		return user.getDefaultSessionType(), nil
+	}
+	for _, sessionType := range user.SessionTypes {
+		if sessionType.Id == sessionTypeId {
+			return sessionType, nil
+		}
+	}
+	log.Printf("GetSessionType: session type(%s) not found for user(%s)\n", sessionTypeId.Hex(), user.Id.Hex())
+	return nil, utils.SessionTypeNotFound
+}
+
+func (user *User) getDefaultSessionType() *SessionType {
+	price := "free"
+	if len(user.Prices) > 0 {
+		price = user.Prices[0].Price
+	}
+	return &SessionType{
+		DurationMinutes: DefaultSessionDurationMinutes,
+		Price:           price,
+	}
+}
+
+func ValidateSessionType(sessionType *SessionType) error {
+	if sessionType.DurationMinutes < MinSessionDurationMinutes || sessionType.DurationMinutes > MaxSessionDurationMinutes {
+		return utils.InvalidSessionType
+	}
+	if sessionType.BufferBeforeMinutes < 0 || sessionType.BufferBeforeMinutes > MaxSessionBufferMinutes ||
+		sessionType.BufferAfterMinutes < 0 || sessionType.BufferAfterMinutes > MaxSessionBufferMinutes {
+		return utils.InvalidSessionType
+	}
+	if sessionType.Id.IsZero() {
+		sessionType.Id = primitive.NewObjectID()
+	}
+	if sessionType.Price == "" {
+		sessionType.Price = "free"
+	}
+	return nil
+}
+
+func (sessionType *SessionType) GetDuration() time.Duration {
+	return time.Duration(sessionType.DurationMinutes) * time.Minute
+}
+
+func (sessionType *SessionType) GetBlockedTimeRange(timeStart time.Time) TimeSlot {
+	return getBlockedTimeRange(timeStart, timeStart.Add(sessionType.GetDuration()), sessionType.BufferBeforeMinutes, sessionType.BufferAfterMinutes)
+}
+
+func (session *Session) SetSessionType(sessionType *SessionType) {
+	session.SessionTypeId = sessionType.Id
+	session.DurationMinutes = sessionType.DurationMinutes
+	session.BufferBeforeMinutes = sessionType.BufferBeforeMinutes
+	session.BufferAfterMinutes = sessionType.BufferAfterMinutes
+	session.PaymentDetails = sessionType.Price
+}
+
+// GetDuration falls back to the default duration for sessions created before session types.
+func (session *Session) GetDuration() time.Duration {
+	if session.DurationMinutes == 0 {
+		return DefaultSessionDurationMinutes * time.Minute
+	}
+	return time.Duration(session.DurationMinutes) * time.Minute
+}
+
+func (session *Session) GetBlockedTimeRange(timeStart, timeEnd time.Time) TimeSlot {
+	return getBlockedTimeRange(timeStart, timeEnd, session.BufferBeforeMinutes, session.BufferAfterMinutes)
+}
+
+// GetBlockedTimeRanges returns the time occupied by the session together with its buffers,
+// including the requested time of a pending reschedule.
+func (session *Session) GetBlockedTimeRanges() []TimeSlot {
+	var result []TimeSlot
+	if session.SessionTimeStart != nil && session.SessionTimeEnd != nil {
+		result = append(result, session.GetBlockedTimeRange(*session.SessionTimeStart, *session.SessionTimeEnd))
+	}
+	if session.NewSessionTimeStart != nil && session.NewSessionTimeEnd != nil {
+		result = append(result, session.GetBlockedTimeRange(*session.NewSessionTimeStart, *session.NewSessionTimeEnd))
+	}
+	return result
+}
+
+// StartsWithin reports whether the session or its pending rescheduled time starts in the range.
+// A session being rescheduled holds both times until the new one is confirmed or rejected.
+func (session *Session) StartsWithin(timeRange TimeSlot) bool {
+	if session.SessionTimeStart != nil && timeRange.Contains(*session.SessionTimeStart) {
+		return true
+	}
+	return session.NewSessionTimeStart != nil && timeRange.Contains(*session.NewSessionTimeStart)
+}
+
+func getBlockedTimeRange(timeStart, timeEnd time.Time, bufferBeforeMinutes, bufferAfterMinutes int) TimeSlot {
+	return TimeSlot{
+		StartTime: timeStart.Add(-time.Duration(bufferBeforeMinutes) * time.Minute),
+		EndTime:   timeEnd.Add(time.Duration(bufferAfterMinutes) * time.Minute),
+	}
+}
+
+func (slot TimeSlot) Overlaps(other TimeSlot) bool {
+	return slot.StartTime.Before(other.EndTime) && other.StartTime.Before(slot.EndTime)
+}
+
+func (slot TimeSlot) Contains(t time.Time) bool {
+	return !t.Before(slot.StartTime) && t.Before(slot.EndTime)
+}
@@ -20,6 +20,8 @@ const (
 	Expired
 )
 
+const MaxCancellationReasonLength = 1000
+
 type Session struct {
 	SessionId           primitive.ObjectID `json:"sessionId" bson:"_id,omitempty"`
 	MentorId            primitive.ObjectID `json:"mentorId" bson:"mentorId"`
@@ -29,6 +31,10 @@ type Session struct {
 	NewSessionTimeStart *time.Time         `json:"newSessionTimeStart,omitempty" bson:"newSessionTimeStart,omitempty"`
 	NewSessionTimeEnd   *time.Time         `json:"newSessionTimeEnd,omitempty" bson:"newSessionTimeEnd,omitempty"`
 	RequestFromMentee   string             `json:"requestFromMentee" bson:"requestFromMentee"`
+	SessionTypeId       primitive.ObjectID `json:"sessionTypeId,omitempty" bson:"sessionTypeId,omitempty"`
+	DurationMinutes     int                `json:"durationMinutes" bson:"durationMinutes,omitempty"`
+	BufferBeforeMinutes int                `json:"-" bson:"bufferBeforeMinutes,omitempty"`
+	BufferAfterMinutes  int                `json:"-" bson:"bufferAfterMinutes,omitempty"`
 	SessionStatus       Status             `json:"-" bson:"sessionStatus"`
 	Status              string             `json:"status" bson:"-"`
 	StatusForMentee     string             `json:"statusForMentee" bson:"-"`
@@ -37,6 +43,11 @@ type Session struct {
 	MeetingLink         string             `json:"meetingLink" bson:"meetingLink,omitempty"`
 	MenteeReview        string             `json:"menteeReview" bson:"menteeReview,omitempty"`
 	MenteeRating        int                `json:"menteeRating" bson:"menteeRating,omitempty"`
+	CancellationReason  string             `json:"cancellationReason,omitempty" bson:"cancellationReason,omitempty"`
+	CanceledBy          primitive.ObjectID `json:"canceledBy,omitempty" bson:"canceledBy,omitempty"`
+	CanceledAt          *time.Time         `json:"canceledAt,omitempty" bson:"canceledAt,omitempty"`
+	ConfirmedAt         *time.Time         `json:"confirmedAt,omitempty" bson:"confirmedAt,omitempty"`
+	Sequence            int                `json:"-" bson:"sequence"`
 	EmailWasSent        bool               `json:"-" bson:"emailWasSent"`
 }
 
@@ -49,6 +60,8 @@ type SessionResponse struct {
 	NewSessionTimeStart *time.Time         `json:"newSessionTimeStart,omitempty" `
 	NewSessionTimeEnd   *time.Time         `json:"newSessionTimeEnd,omitempty"`
 	RequestFromMentee   string             `json:"requestFromMentee"`
+	SessionTypeId       primitive.ObjectID `json:"sessionTypeId,omitempty"`
+	DurationMinutes     int                `json:"durationMinutes"`
 	SessionStatus       Status             `json:"-"`
 	Status              string             `json:"status"`
 	StatusForMentee     string             `json:"statusForMentee"`
@@ -57,6 +70,14 @@ type SessionResponse struct {
 	MeetingLink         string             `json:"meetingLink"`
 	MenteeReview        string             `json:"menteeReview,omitempty"`
 	MenteeRating        int                `json:"menteeRating,omitempty"`
+	CancellationReason  string             `json:"cancellationReason,omitempty"`
+	CanceledBy          primitive.ObjectID `json:"canceledBy,omitempty"`
+	CanceledAt          *time.Time         `json:"canceledAt,omitempty"`
+	Sequence            int                `json:"-"`
+}
+
+type SessionCancellation struct {
+	Reason string `json:"reason"`
 }
 
 type GroupedSessions struct {
@@ -87,6 +108,8 @@ type SessionNotification struct {
 	MenteeEmail      string             `json:"menteeEmail" bson:"menteeEmail"`
 	MentorName       string             `json:"mentorName" bson:"mentorName"`
 	MentorEmail      string             `json:"mentorEmail" bson:"mentorEmail"`
+	MenteeLanguage   string             `json:"-" bson:"menteeLanguage"`
+	MentorLanguage   string             `json:"-" bson:"mentorLanguage"`
 }
 
 func (s Status) GetStatusForMentee() string {
@@ -170,9 +193,11 @@ func SetStatusText(session *Session) {
 	session.Status = session.SessionStatus.String()
 }
 
-func GetSessionTime(session *SessionResponse) (string, string) {
+// GetSessionTime formats the session start in the given IANA time zone of the recipient.
+func GetSessionTime(session *SessionResponse, timeZone string) (string, string) {
 	if session.SessionTimeStart != nil {
-		return session.SessionTimeStart.Format(utils.DateLayout), session.SessionTimeStart.Format(utils.TimeLayout)
+		sessionTimeStart := session.SessionTimeStart.In(utils.GetLocation(timeZone))
+		return sessionTimeStart.Format(utils.DateLayout), sessionTimeStart.Format(utils.TimeLayout)
 	}
 	return "N/A", "N/A"
 }
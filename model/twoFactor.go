@@ -0,0 +1,69 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	TwoFactorChallengeTTL         = 5 * time.Minute
+	MaxTwoFactorChallengeAttempts = 5
+	RecoveryCodesCount            = 10
+	// MaxTwoFactorFailures invalid codes in a row lock the second factor of the user for
+	// TwoFactorLockout, whatever challenge they were sent for.
+	MaxTwoFactorFailures = 10
+	TwoFactorLockout     = 15 * time.Minute
+)
+
+// TwoFactorAuth is the TOTP setup of the user. The secret is stored on enrolment and the
+// second factor is enforced once the first code is confirmed. Recovery codes are stored hashed.
+type TwoFactorAuth struct {
+	Secret         string     `bson:"secret"`
+	Enabled        bool       `bson:"enabled"`
+	EnabledAt      *time.Time `bson:"enabledAt,omitempty"`
+	RecoveryCodes  []string   `bson:"recoveryCodes,omitempty"`
+	LastUsedStep   int64      `bson:"lastUsedStep"`
+	FailedAttempts int        `bson:"failedAttempts"`
+	LockedUntil    *time.Time `bson:"lockedUntil,omitempty"`
+}
+
+func (user *User) IsTwoFactorEnabled() bool {
+	return user.TwoFactor != nil && user.TwoFactor.Enabled
+}
+
+func (user *User) IsTwoFactorLocked(now time.Time) bool {
+	return user.TwoFactor != nil && user.TwoFactor.LockedUntil != nil && now.Before(*user.TwoFactor.LockedUntil)
+}
+
+// TwoFactorChallenge is issued after the password check of a user with 2FA. It is stored under
+// the hash of the token given to the client and removed by a TTL index on ExpiresAt.
+type TwoFactorChallenge struct {
+	TokenHash string             `bson:"_id"`
+	UserId    primitive.ObjectID `bson:"userId"`
+	Attempts  int                `bson:"attempts"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"twoFactorRequired"`
+	Challenge         string    `json:"challenge"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+type TwoFactorSignIn struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/url"
+	"oysterProject/utils"
+	"time"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookSessionCreated     = "session.created"
+	WebhookSessionConfirmed   = "session.confirmed"
+	WebhookSessionRescheduled = "session.rescheduled"
+	WebhookSessionCancelled   = "session.cancelled"
+	WebhookReviewCreated      = "review.created"
+	WebhookUserApproved       = "user.approved"
+
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySending   WebhookDeliveryStatus = "sending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "dead"
+
+	MaxWebhookAttempts = 8
+)
+
+var WebhookEvents = []string{
+	WebhookSessionCreated, WebhookSessionConfirmed, WebhookSessionRescheduled,
+	WebhookSessionCancelled, WebhookReviewCreated, WebhookUserApproved,
+}
+
+// WebhookSubscription receives the listed events. The secret is returned only when the
+// subscription is created and signs every delivery.
+type WebhookSubscription struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	URL       string             `json:"url" bson:"url"`
+	Events    []string           `json:"events" bson:"events"`
+	Secret    string             `json:"secret,omitempty" bson:"secret"`
+	Active    *bool              `json:"active" bson:"active"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+func (subscription *WebhookSubscription) IsActive() bool {
+	return subscription.Active != nil && *subscription.Active
+}
+
+// WebhookPayload is the JSON body of every delivery.
+type WebhookPayload struct {
+	Id        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// WebhookDelivery is one event sent to one subscription, kept as the delivery log. A replay
+// is a new delivery of the same payload.
+type WebhookDelivery struct {
+	Id             primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	SubscriptionId primitive.ObjectID    `json:"subscriptionId" bson:"subscriptionId"`
+	EventId        string                `json:"eventId" bson:"eventId"`
+	Event          string                `json:"event" bson:"event"`
+	Payload        string                `json:"payload" bson:"payload"`
+	DedupeKey      string                `json:"-" bson:"dedupeKey,omitempty"`
+	ReplayOf       *primitive.ObjectID   `json:"replayOf,omitempty" bson:"replayOf,omitempty"`
+	Status         WebhookDeliveryStatus `json:"status" bson:"status"`
+	Attempts       int                   `json:"attempts" bson:"attempts"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt" bson:"nextAttemptAt"`
+	ResponseStatus int                   `json:"responseStatus,omitempty" bson:"responseStatus,omitempty"`
+	ResponseBody   string                `json:"responseBody,omitempty" bson:"responseBody,omitempty"`
+	LastError      string                `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	ClaimedBy      string                `json:"-" bson:"claimedBy,omitempty"`
+	ClaimedUntil   *time.Time            `json:"-" bson:"claimedUntil,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt" bson:"createdAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty" bson:"deliveredAt,omitempty"`
+}
+
+// WebhookUser is the data of user events, the user document itself holds private fields.
+type WebhookUser struct {
+	UserId primitive.ObjectID `json:"userId"`
+	Name   string             `json:"name"`
+	Email  string             `json:"email"`
+}
+
+// WebhookSession is the data of session events. It leaves out the meeting link, the payment
+// details and the emails of the participants, which are not for third parties.
+type WebhookSession struct {
+	SessionId           primitive.ObjectID `json:"sessionId"`
+	MentorId            primitive.ObjectID `json:"mentorId"`
+	MenteeId            primitive.ObjectID `json:"menteeId"`
+	SessionTimeStart    *time.Time         `json:"sessionTimeStart"`
+	SessionTimeEnd      *time.Time         `json:"sessionTimeEnd"`
+	NewSessionTimeStart *time.Time         `json:"newSessionTimeStart,omitempty"`
+	NewSessionTimeEnd   *time.Time         `json:"newSessionTimeEnd,omitempty"`
+	Status              string             `json:"status"`
+	CanceledBy          primitive.ObjectID `json:"canceledBy,omitempty"`
+	CanceledAt          *time.Time         `json:"canceledAt,omitempty"`
+}
+
+// WebhookReview is the data of review events.
+type WebhookReview struct {
+	ReviewId  primitive.ObjectID `json:"reviewId"`
+	SessionId primitive.ObjectID `json:"sessionId,omitempty"`
+	MentorId  primitive.ObjectID `json:"mentorId"`
+	MenteeId  primitive.ObjectID `json:"menteeId,omitempty"`
+	Rating    int                `json:"rating"`
+	Date      *time.Time         `json:"date"`
+}
+
+func ValidateWebhookSubscription(subscription *WebhookSubscription) error {
+	parsedURL, err := url.Parse(subscription.URL)
+	if err != nil || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") || parsedURL.Host == "" {
+		return utils.InvalidWebhookSubscription
+	}
+	if len(subscription.Events) == 0 {
+		return utils.InvalidWebhookSubscription
+	}
+	for _, event := range subscription.Events {
+		if !utils.Contains(WebhookEvents, event) {
+			return utils.InvalidWebhookSubscription
+		}
+	}
+	return nil
+}
@@ -16,8 +16,12 @@ func ConfigureRoutes(r *chi.Mux) {
 		r.Post("/", httpHandlers.HandleEmailPassAuth)
 		r.Get("/google", httpHandlers.HandleGoogleAuth)
 		r.Get("/google/callback", httpHandlers.HandleAuthCallback)
+		r.Post("/forgotPassword", httpHandlers.ForgotPassword)
+		r.Post("/resetPassword", httpHandlers.ResetPassword)
+		r.Post("/verifyEmail", httpHandlers.VerifyEmail)
 	})
 	r.Post("/signIn", httpHandlers.SignIn)
+	r.Post("/signIn/twoFactor", httpHandlers.SignInTwoFactor)
 	r.With(httpHandlers.AuthMiddleware).Post("/signOut", httpHandlers.SignOut)
 	r.With(httpHandlers.AuthMiddleware).Post("/refreshAuthSession", httpHandlers.RefreshAuthSession)
 
@@ -34,15 +38,48 @@ func ConfigureRoutes(r *chi.Mux) {
 
 	r.Get("/getUserAvailableWeekdays", httpHandlers.GetUserAvailableWeekdays)
 	r.Get("/getUserAvailableSlots", httpHandlers.GetUserAvailableSlots)
+	r.Get("/calendar/{token}.ics", httpHandlers.GetCalendarFeed)
+	r.Post("/webhooks/sendgrid", httpHandlers.HandleSendGridEvents)
+	r.Route("/notifications", func(r chi.Router) {
+		r.Get("/unsubscribe/{token}", httpHandlers.GetUnsubscribePage)
+		r.Post("/unsubscribe/{token}", httpHandlers.Unsubscribe)
+		r.With(httpHandlers.AuthMiddleware).Group(func(r chi.Router) {
+			r.Get("/", httpHandlers.GetNotifications)
+			r.Get("/unreadCount", httpHandlers.GetUnreadNotificationsCount)
+			r.Get("/stream", httpHandlers.StreamNotifications)
+			r.Post("/readAll", httpHandlers.MarkAllNotificationsRead)
+			r.Post("/{notificationId}/read", httpHandlers.MarkNotificationRead)
+		})
+	})
 
 	r.With(httpHandlers.AuthMiddleware).Route("/myProfile", func(r chi.Router) {
 		r.Get("/", httpHandlers.GetProfileByToken)
 		r.Post("/update", httpHandlers.UpdateUserProfile)
 		r.Post("/visibility", httpHandlers.UpdateVisibility)
 		r.Post("/updatePassword", httpHandlers.ChangePassword)
+		r.Post("/resendVerificationEmail", httpHandlers.ResendVerificationEmail)
+		r.Route("/sessions", func(r chi.Router) {
+			r.Get("/", httpHandlers.GetAuthSessions)
+			r.Post("/signOutOthers", httpHandlers.RevokeOtherAuthSessions)
+			r.Delete("/{sessionId}", httpHandlers.RevokeAuthSession)
+		})
+		r.Route("/twoFactor", func(r chi.Router) {
+			r.Post("/enroll", httpHandlers.EnrollTwoFactor)
+			r.Post("/confirm", httpHandlers.ConfirmTwoFactor)
+			r.Post("/disable", httpHandlers.DisableTwoFactor)
+		})
 		r.Get("/getCurrentState", httpHandlers.GetCurrentState)
 		r.Post("/updateCurrentState", httpHandlers.UpdateCurrentState)
 		r.Post("/uploadProfilePicture", httpHandlers.UploadUserImage)
+		r.Post("/calendar/rotateToken", httpHandlers.RotateCalendarToken)
+		r.Get("/notifications", httpHandlers.GetNotificationPreferences)
+		r.Post("/notifications", httpHandlers.UpdateNotificationPreferences)
+		r.Route("/availability/exceptions", func(r chi.Router) {
+			r.Get("/", httpHandlers.GetAvailabilityExceptions)
+			r.Post("/", httpHandlers.CreateAvailabilityException)
+			r.Post("/{exceptionId}", httpHandlers.UpdateAvailabilityException)
+			r.Delete("/{exceptionId}", httpHandlers.DeleteAvailabilityException)
+		})
 	})
 
 	r.With(httpHandlers.AuthMiddleware).Route("/session", func(r chi.Router) {
@@ -56,6 +93,23 @@ func ConfigureRoutes(r *chi.Mux) {
 	})
 
 	r.With(httpHandlers.AuthMiddleware).Post("/createPublicReview", httpHandlers.CreatePublicReview)
+
+	r.With(httpHandlers.AuthMiddleware, httpHandlers.AdminMiddleware).Route("/admin", func(r chi.Router) {
+		r.Get("/jobs", httpHandlers.GetJobs)
+		r.Get("/jobs/runs", httpHandlers.GetJobRuns)
+		r.Post("/jobs/{jobName}/trigger", httpHandlers.TriggerJob)
+		r.Get("/emails/templates", httpHandlers.GetEmailTemplates)
+		r.Get("/emails/preview", httpHandlers.PreviewEmailTemplate)
+		r.Get("/users/{userId}/emails", httpHandlers.GetUserEmails)
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", httpHandlers.GetWebhookSubscriptions)
+			r.Post("/", httpHandlers.CreateWebhookSubscription)
+			r.Post("/{webhookId}", httpHandlers.UpdateWebhookSubscription)
+			r.Delete("/{webhookId}", httpHandlers.DeleteWebhookSubscription)
+			r.Get("/{webhookId}/deliveries", httpHandlers.GetWebhookDeliveries)
+			r.Post("/deliveries/{deliveryId}/replay", httpHandlers.ReplayWebhookDelivery)
+		})
+	})
 }
 
 func ConfigureCors(r *chi.Mux) {
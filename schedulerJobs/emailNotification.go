@@ -2,85 +2,146 @@ package schedulerJobs
 
 import (
 	"context"
+	"errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"log"
 	"oysterProject/database"
 	"oysterProject/emailNotifications"
+	"oysterProject/inAppNotifications"
 	"oysterProject/model"
+	"oysterProject/utils"
+	"oysterProject/webhooks"
 	"time"
 )
 
-func sendUpcomingSessionNotification() {
-	runJobWithTimeout(func(ctx context.Context) {
-		sessionCollection := database.GetCollection(database.SessionCollectionName)
-		currentTime := time.Now().UTC()
-		filterPipeline := database.GetSessionsForNotificationPipeline(currentTime.Add(notificationTimeBeforeSession), currentTime.Add(2*notificationTimeBeforeSession))
-		cursor, err := sessionCollection.Aggregate(ctx, filterPipeline)
-		defer cursor.Close(ctx)
-		if err != nil {
-			log.Printf("SendUpcomingSessionNotification: Error executing search in db: %v", err)
-			return
-		}
-
-		var sessions []model.SessionNotification
-		if err = cursor.All(ctx, &sessions); err != nil {
-			log.Printf("SendUpcomingSessionNotification: Failed to fetch sessions: %v", err)
-			return
-		}
-		log.Printf("sendUpcomingSessionNotification count: %v\n", len(sessions))
-		for _, session := range sessions {
-			timeForNotification := session.SessionTimeStart.Sub(currentTime) - notificationTimeBeforeSession
-			if timeForNotification <= 0 {
-				continue
-			}
-			createRoutine(emailNotifications.SendNotificationBeforeSession, &session, timeForNotification)
-		}
-	})
+// createSessionReminders persists reminders for sessions starting within the biggest reminder
+// offset. The look-ahead covers a few job intervals, so a restart does not lose reminders.
+func createSessionReminders(ctx context.Context, run *model.JobRun) error {
+	currentTime := time.Now().UTC()
+	lookAhead := getMaxReminderOffset() + 2*createSessionRemindersInterval
+	createdCount, err := database.CreateSessionReminders(ctx, currentTime, currentTime.Add(lookAhead))
+	if err != nil {
+		log.Printf("createSessionReminders: Error creating reminders: %v", err)
+		return err
+	}
+	log.Printf("createSessionReminders count: %v\n", createdCount)
+	run.AddCount("created", createdCount)
+	return nil
 }
 
-func sendReviewEmails() {
-	runJobWithTimeout(func(ctx context.Context) {
-		sessionCollection := database.GetCollection(database.SessionCollectionName)
-		filter := database.GetSessionsForReviewNotificationPipeline()
-		cursor, err := sessionCollection.Aggregate(ctx, filter)
-		defer cursor.Close(ctx)
-		if err != nil {
-			log.Printf("SendReviewEmails: Error executing search in db: %v", err)
-			return
+// sendSessionReminders sends due reminders one by one. Every reminder is claimed atomically,
+// so with several instances each reminder is sent once.
+func sendSessionReminders(ctx context.Context, run *model.JobRun) error {
+	for {
+		reminder, err := database.ClaimDueSessionReminder(ctx, instanceId, time.Now().UTC())
+		if err != nil || reminder == nil {
+			return err
 		}
-		var sessions []model.SessionNotification
-		if err = cursor.All(ctx, &sessions); err != nil {
-			log.Printf("SendReviewEmails: Failed to fetch reviews: %v", err)
-			return
+		status := sendSessionReminder(ctx, reminder)
+		if err = database.CompleteSessionReminder(ctx, reminder, status); err != nil {
+			return err
 		}
-		log.Printf("sendReviewEmails count: %v\n", len(sessions))
-		for _, session := range sessions {
-			go emailNotifications.SendReviewEmails(&session)
+		run.AddCount(string(status), 1)
+		if status == model.ReminderPending {
+			// the reminder is retried on the next run
+			return nil
 		}
-	})
+	}
 }
 
-func sendEmailForApprovedUsers() {
-	runJobWithTimeout(func(ctx context.Context) {
-		userCollection := database.GetCollection(database.UserCollectionName)
-		filter := bson.M{
-			"approvedEmailWasSent": false,
-			"isApproved":           true,
+func sendSessionReminder(ctx context.Context, reminder *model.SessionReminder) model.ReminderStatus {
+	session, err := database.GetSessionNotification(ctx, reminder.SessionId, reminder.SessionTimeStart)
+	if err != nil {
+		if reminder.Attempts >= model.MaxReminderAttempts {
+			return model.ReminderFailed
 		}
-		cursor, err := userCollection.Find(ctx, filter)
-		defer cursor.Close(ctx)
-		if err != nil {
-			log.Printf("sendEmailForApprovedUsers: Error executing search in db: %v", err)
-			return
+		return model.ReminderPending
+	}
+	if session == nil {
+		log.Printf("sendSessionReminder: session(%s) was rescheduled or canceled, reminder skipped\n", reminder.SessionId.Hex())
+		return model.ReminderSkipped
+	}
+	emailNotifications.SendNotificationBeforeSession(session, reminder.GetOffset())
+	return model.ReminderSent
+}
+
+// sendQueuedEmails delivers due emails from the outbox. Every email is claimed atomically, so
+// with several instances each email is sent once; failed emails wait for their next attempt.
+func sendQueuedEmails(ctx context.Context, run *model.JobRun) error {
+	for ctx.Err() == nil {
+		email, err := database.ClaimDueEmail(ctx, instanceId, time.Now().UTC())
+		if err != nil || email == nil {
+			return err
 		}
-		var users []model.User
-		if err = cursor.All(ctx, &users); err != nil {
-			log.Printf("sendEmailForApprovedUsers: Failed to fetch users: %v", err)
-			return
+		sendErr := emailNotifications.DeliverEmail(email)
+		status, err := database.CompleteEmail(ctx, email, sendErr)
+		if errors.Is(err, utils.EmailClaimLost) {
+			// another instance claimed the email again and stores its own result
+			run.AddCount("claimLost", 1)
+			continue
+		} else if err != nil {
+			return err
 		}
-		log.Printf("sendEmailForApprovedUsers count: %v\n", len(users))
-		for _, user := range users {
-			go emailNotifications.SendApprovedEmail(&user)
+		run.AddCount(string(status), 1)
+	}
+	return ctx.Err()
+}
+
+func getMaxReminderOffset() time.Duration {
+	var maxOffset time.Duration
+	for _, offset := range model.SessionReminderOffsets {
+		if offset > maxOffset {
+			maxOffset = offset
 		}
-	})
+	}
+	return maxOffset
+}
+
+func sendReviewEmails(ctx context.Context, run *model.JobRun) error {
+	sessionCollection := database.GetCollection(database.SessionCollectionName)
+	filter := database.GetSessionsForReviewNotificationPipeline()
+	cursor, err := sessionCollection.Aggregate(ctx, filter)
+	if err != nil {
+		log.Printf("SendReviewEmails: Error executing search in db: %v", err)
+		return err
+	}
+	defer cursor.Close(ctx)
+	var sessions []model.SessionNotification
+	if err = cursor.All(ctx, &sessions); err != nil {
+		log.Printf("SendReviewEmails: Failed to fetch reviews: %v", err)
+		return err
+	}
+	log.Printf("sendReviewEmails count: %v\n", len(sessions))
+	run.AddCount("sessions", int64(len(sessions)))
+	for i := range sessions {
+		go emailNotifications.SendReviewEmails(&sessions[i])
+	}
+	return nil
+}
+
+func sendEmailForApprovedUsers(ctx context.Context, run *model.JobRun) error {
+	userCollection := database.GetCollection(database.UserCollectionName)
+	filter := bson.M{
+		"approvedEmailWasSent": false,
+		"isApproved":           true,
+	}
+	cursor, err := userCollection.Find(ctx, filter)
+	if err != nil {
+		log.Printf("sendEmailForApprovedUsers: Error executing search in db: %v", err)
+		return err
+	}
+	defer cursor.Close(ctx)
+	var users []model.User
+	if err = cursor.All(ctx, &users); err != nil {
+		log.Printf("sendEmailForApprovedUsers: Failed to fetch users: %v", err)
+		return err
+	}
+	log.Printf("sendEmailForApprovedUsers count: %v\n", len(users))
+	run.AddCount("users", int64(len(users)))
+	for i := range users {
+		go emailNotifications.SendApprovedEmail(&users[i])
+		go inAppNotifications.NotifyUserApproved(&users[i])
+		go webhooks.EmitUserApproved(&users[i])
+	}
+	return nil
 }
@@ -0,0 +1,127 @@
+package schedulerJobs
+
+import (
+	"context"
+	"log"
+	"oysterProject/database"
+	"oysterProject/model"
+	"time"
+)
+
+const (
+	jobLockTTL           = time.Minute
+	jobLockRenewInterval = jobLockTTL / 3
+)
+
+// runWithJobLock runs the job only on the instance that acquires its lease. The lease is
+// renewed while the job runs and released with the finish time when it is done. Manual
+// runs ignore how recently the job finished.
+func runWithJobLock(j *job, trigger string) {
+	acquired, err := acquireJobLock(j, trigger)
+	if err != nil || !acquired {
+		return
+	}
+	runLockedJob(j, trigger)
+}
+
+func acquireJobLock(j *job, trigger string) (bool, error) {
+	minInterval := j.interval / 2
+	if trigger == model.JobTriggerManual {
+		minInterval = 0
+	}
+	acquired, err := database.AcquireJobLock(j.name, instanceId, jobLockTTL, minInterval)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		logJobLockOwner(j.name)
+		return false, nil
+	}
+	log.Printf("Job %s: lock acquired by %s\n", j.name, instanceId)
+	return true, nil
+}
+
+// runLockedJob runs the job whose lock was acquired by this instance and releases it after.
+func runLockedJob(j *job, trigger string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go renewJobLock(ctx, cancel, j.name)
+	defer func() {
+		cancel()
+		if err := database.ReleaseJobLock(j.name, instanceId); err == nil {
+			log.Printf("Job %s: lock released by %s\n", j.name, instanceId)
+		}
+	}()
+
+	recordJobRun(ctx, j, trigger)
+}
+
+// recordJobRun stores the run in the job history together with its counts and error.
+func recordJobRun(ctx context.Context, j *job, trigger string) {
+	run := &model.JobRun{
+		JobName:   j.name,
+		Instance:  instanceId,
+		Trigger:   trigger,
+		Status:    model.JobRunRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	_ = database.InsertJobRun(run)
+
+	err := runJobWithTimeout(ctx, run, j.run)
+
+	finishedAt := time.Now().UTC()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Status = model.JobRunSucceeded
+	if err != nil {
+		run.Status = model.JobRunFailed
+		run.Error = err.Error()
+		log.Printf("Job %s failed: %v\n", j.name, err)
+	}
+	log.Printf("Job %s finished in %dms, counts: %v\n", j.name, run.DurationMs, run.Counts)
+	if !run.Id.IsZero() {
+		_ = database.FinishJobRun(run)
+	}
+}
+
+// renewJobLock extends the lease until ctx is done. The job is cancelled when the lease is taken
+// over or could not be renewed before it expired, so two instances never run it at once.
+func renewJobLock(ctx context.Context, cancelJob context.CancelFunc, jobName string) {
+	ticker := time.NewTicker(jobLockRenewInterval)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := database.RenewJobLock(jobName, instanceId, jobLockTTL)
+			if err == nil && renewed {
+				renewedAt = time.Now()
+				continue
+			}
+			if err == nil {
+				log.Printf("Job %s: lock of %s was lost while the job is running, cancelling\n", jobName, instanceId)
+				cancelJob()
+				return
+			}
+			if time.Since(renewedAt) >= jobLockTTL {
+				log.Printf("Job %s: lock of %s expired while the job is running, cancelling\n", jobName, instanceId)
+				cancelJob()
+				return
+			}
+		}
+	}
+}
+
+func logJobLockOwner(jobName string) {
+	jobLock, err := database.GetJobLock(jobName)
+	if err != nil {
+		log.Printf("Job %s: skipped on %s, lock is not available\n", jobName, instanceId)
+		return
+	}
+	if jobLock.ExpiresAt.After(time.Now()) || jobLock.LastFinishedAt == nil {
+		log.Printf("Job %s: skipped on %s, lock is owned by %s until %s\n", jobName, instanceId, jobLock.Owner, jobLock.ExpiresAt.Format(time.RFC3339))
+		return
+	}
+	log.Printf("Job %s: skipped on %s, last run finished by %s at %s\n", jobName, instanceId, jobLock.Owner, jobLock.LastFinishedAt.Format(time.RFC3339))
+}
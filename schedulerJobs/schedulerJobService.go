@@ -1,58 +1,125 @@
 package schedulerJobs
 
 import (
+	"context"
+	"fmt"
 	"github.com/go-co-op/gocron"
 	"log"
+	"os"
 	"oysterProject/model"
 	"oysterProject/utils"
 	"time"
 )
 
 const (
-	statusCalculationInterval     = 30 * time.Minute
-	deleteExpiredSessionsInterval = 24 * time.Hour
-	sendUpcomingSessionInterval   = 1 * time.Hour
-	notificationTimeBeforeSession = 30 * time.Minute
-	dbTimeout                     = 5 * time.Minute
-	reviewsEmailInterval          = 15 * time.Minute
-	approvedUserEmailInterval     = 60 * time.Minute
+	statusCalculationInterval      = 30 * time.Minute
+	deleteExpiredSessionsInterval  = 24 * time.Hour
+	createSessionRemindersInterval = 15 * time.Minute
+	sendSessionRemindersInterval   = 1 * time.Minute
+	sendQueuedEmailsInterval       = 1 * time.Minute
+	deliverWebhooksInterval        = 1 * time.Minute
+	dbTimeout                      = 5 * time.Minute
+	reviewsEmailInterval           = 15 * time.Minute
+	approvedUserEmailInterval      = 60 * time.Minute
 )
 
-var (
-	notificationJobDelay = time.Duration(30-time.Now().Minute()%30) * time.Minute
-)
+// instanceId identifies this process in claims and locks shared between instances.
+var instanceId = getInstanceId()
 
-func StartJobs() {
-	startAsyncJob(statusCalculation, statusCalculationInterval, 0)
-	startAsyncJob(deleteExpired, deleteExpiredSessionsInterval, 0)
-	startAsyncJob(sendUpcomingSessionNotification, sendUpcomingSessionInterval, notificationJobDelay)
-	startAsyncJob(sendReviewEmails, reviewsEmailInterval, 0)
-	startAsyncJob(sendEmailForApprovedUsers, approvedUserEmailInterval, 0)
+type jobFunc func(ctx context.Context, run *model.JobRun) error
+
+type job struct {
+	name         string
+	interval     time.Duration
+	run          jobFunc
+	schedulerJob *gocron.Job
+}
+
+var jobs = []*job{
+	{name: "statusCalculation", interval: statusCalculationInterval, run: statusCalculation},
+	{name: "deleteExpired", interval: deleteExpiredSessionsInterval, run: deleteExpired},
+	{name: "createSessionReminders", interval: createSessionRemindersInterval, run: createSessionReminders},
+	{name: "sendSessionReminders", interval: sendSessionRemindersInterval, run: sendSessionReminders},
+	{name: "sendReviewEmails", interval: reviewsEmailInterval, run: sendReviewEmails},
+	{name: "sendEmailForApprovedUsers", interval: approvedUserEmailInterval, run: sendEmailForApprovedUsers},
+	{name: "sendQueuedEmails", interval: sendQueuedEmailsInterval, run: sendQueuedEmails},
+	{name: "deliverWebhooks", interval: deliverWebhooksInterval, run: deliverWebhooks},
 }
 
-func startAsyncJob(jobFunc func(), interval, delay time.Duration) {
-	j := gocron.NewScheduler(time.UTC).Every(interval)
-	if delay == 0 {
-		j.StartImmediately()
-	} else {
-		j.StartAt(time.Now().UTC().Add(delay))
+func StartJobs() {
+	for _, j := range jobs {
+		startAsyncJob(j)
 	}
-	_, err := j.Do(jobFunc)
+}
+
+func startAsyncJob(j *job) {
+	scheduler := gocron.NewScheduler(time.UTC).Every(j.interval).StartImmediately()
+	schedulerJob, err := scheduler.Do(runWithJobLock, j, model.JobTriggerSchedule)
 	if err != nil {
-		log.Fatalf("Error initializing job(%s): %v\n", utils.GetFunctionName(jobFunc), err)
+		log.Fatalf("Error initializing job(%s): %v\n", j.name, err)
 		return
 	}
-	j.StartAsync()
+	j.schedulerJob = schedulerJob
+	scheduler.StartAsync()
 }
 
-func createRoutine(jobFunc func(session *model.SessionNotification), session *model.SessionNotification, delay time.Duration) {
-	go func() {
-		timer := time.NewTimer(delay)
-		defer timer.Stop()
-		log.Printf("Timer was created with delay: %s\n", delay)
-		select {
-		case <-timer.C:
-			jobFunc(session)
+func getJob(name string) (*job, error) {
+	for _, j := range jobs {
+		if j.name == name {
+			return j, nil
 		}
-	}()
+	}
+	return nil, utils.JobNotFound
+}
+
+// TriggerJob starts the job outside of its schedule. The run still needs the job lock, so it
+// fails with JobAlreadyRunning when an instance is running the job right now.
+func TriggerJob(name string) error {
+	j, err := getJob(name)
+	if err != nil {
+		return err
+	}
+	acquired, err := acquireJobLock(j, model.JobTriggerManual)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return utils.JobAlreadyRunning
+	}
+	go runLockedJob(j, model.JobTriggerManual)
+	return nil
+}
+
+func GetJobNames() []string {
+	var names []string
+	for _, j := range jobs {
+		names = append(names, j.name)
+	}
+	return names
+}
+
+// GetJobSchedule returns the interval and the next scheduled run of the job on this instance.
+func GetJobSchedule(name string) (time.Duration, time.Time, error) {
+	j, err := getJob(name)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if j.schedulerJob == nil {
+		return j.interval, time.Time{}, nil
+	}
+	return j.interval, j.schedulerJob.NextRun(), nil
+}
+
+func runJobWithTimeout(ctx context.Context, run *model.JobRun, jobFunc jobFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, dbTimeout)
+	defer cancel()
+	return jobFunc(ctx, run)
+}
+
+func getInstanceId() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
 }
@@ -11,52 +11,51 @@ import (
 	"time"
 )
 
-func statusCalculation() {
-	runJobWithTimeout(func(ctx context.Context) {
-		sessionCollection := database.GetCollection(database.SessionCollectionName)
+func statusCalculation(ctx context.Context, run *model.JobRun) error {
+	sessionCollection := database.GetCollection(database.SessionCollectionName)
 
-		filterExpired := bson.M{
-			"sessionTimeEnd": bson.M{"$lt": time.Now()},
-			"sessionStatus":  bson.M{"$lt": model.Confirmed},
-		}
-		filterCompleted := bson.M{
-			"sessionTimeStart": bson.M{"$lt": time.Now()},
-			"sessionStatus":    model.Confirmed,
-		}
-		updateExpired := bson.M{"$set": bson.M{"sessionStatus": model.Expired}}
-		updateCompleted := bson.M{"$set": bson.M{"sessionStatus": model.Completed}}
-
-		runUpdateManyJob(ctx, sessionCollection, filterExpired, updateExpired, model.Expired.String())
-		runUpdateManyJob(ctx, sessionCollection, filterCompleted, updateCompleted, model.Completed.String())
-	})
-}
+	filterExpired := bson.M{
+		"sessionTimeEnd": bson.M{"$lt": time.Now()},
+		"sessionStatus":  bson.M{"$lt": model.Confirmed},
+	}
+	filterCompleted := bson.M{
+		"sessionTimeStart": bson.M{"$lt": time.Now()},
+		"sessionStatus":    model.Confirmed,
+	}
+	updateExpired := bson.M{"$set": bson.M{"sessionStatus": model.Expired}}
+	updateCompleted := bson.M{"$set": bson.M{"sessionStatus": model.Completed}}
 
-func deleteExpired() {
-	runJobWithTimeout(func(ctx context.Context) {
-		collection := database.GetCollection(database.AuthSessionCollectionName)
-		filter := bson.M{"expiry": bson.M{"$lt": time.Now().Unix()}}
-		runDeleteManyJob(ctx, collection, filter)
-	})
+	if err := runUpdateManyJob(ctx, run, sessionCollection, filterExpired, updateExpired, model.Expired.String()); err != nil {
+		return err
+	}
+	return runUpdateManyJob(ctx, run, sessionCollection, filterCompleted, updateCompleted, model.Completed.String())
 }
 
-func runJobWithTimeout(jobFunc func(ctx context.Context)) {
-	ctx, cancel := context.WithTimeout(context.TODO(), dbTimeout)
-	defer cancel()
-	jobFunc(ctx)
+func deleteExpired(ctx context.Context, run *model.JobRun) error {
+	collection := database.GetCollection(database.AuthSessionCollectionName)
+	filter := bson.M{"expiry": bson.M{"$lt": time.Now().Unix()}}
+	return runDeleteManyJob(ctx, run, collection, filter)
 }
 
-func runUpdateManyJob(ctx context.Context, collection *mongo.Collection, filter, update bson.M, statusType string) {
+func runUpdateManyJob(ctx context.Context, run *model.JobRun, collection *mongo.Collection, filter, update bson.M, statusType string) error {
 	result, err := collection.UpdateMany(ctx, filter, update)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 		log.Printf("UpdateMany job error: %v\n", err)
+		return err
 	}
 	log.Printf("Matched %v documents and modified %v documents for %s status\n", result.MatchedCount, result.ModifiedCount, statusType)
+	run.AddCount(statusType+"Matched", result.MatchedCount)
+	run.AddCount(statusType+"Modified", result.ModifiedCount)
+	return nil
 }
 
-func runDeleteManyJob(ctx context.Context, collection *mongo.Collection, filter bson.M) {
+func runDeleteManyJob(ctx context.Context, run *model.JobRun, collection *mongo.Collection, filter bson.M) error {
 	result, err := collection.DeleteMany(ctx, filter)
 	if err != nil {
 		log.Printf("DeleteMany job error: %v\n", err)
+		return err
 	}
 	log.Printf("Deleted count: %v\n", result.DeletedCount)
+	run.AddCount("deleted", result.DeletedCount)
+	return nil
 }
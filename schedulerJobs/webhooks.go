@@ -0,0 +1,51 @@
+package schedulerJobs
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"oysterProject/database"
+	"oysterProject/model"
+	"oysterProject/webhooks"
+	"time"
+)
+
+var errWebhookSubscriptionRemoved = errors.New("webhook subscription was deleted or deactivated")
+
+// deliverWebhooks posts due webhook deliveries one by one. Every delivery is claimed
+// atomically, so with several instances each delivery is posted once per attempt.
+func deliverWebhooks(ctx context.Context, run *model.JobRun) error {
+	subscriptions := make(map[primitive.ObjectID]*model.WebhookSubscription)
+	for ctx.Err() == nil {
+		delivery, err := database.ClaimDueWebhookDelivery(ctx, instanceId, time.Now().UTC())
+		if err != nil || delivery == nil {
+			return err
+		}
+		subscription, ok := subscriptions[delivery.SubscriptionId]
+		if !ok {
+			subscription, err = database.GetWebhookSubscription(ctx, delivery.SubscriptionId)
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return err
+			}
+			subscriptions[delivery.SubscriptionId] = subscription
+		}
+
+		var responseStatus int
+		var responseBody string
+		var sendErr error
+		if subscription == nil || !subscription.IsActive() {
+			// nobody listens anymore, retrying is pointless
+			delivery.Attempts = model.MaxWebhookAttempts
+			sendErr = errWebhookSubscriptionRemoved
+		} else {
+			responseStatus, responseBody, sendErr = webhooks.Deliver(ctx, delivery, subscription)
+		}
+		status, err := database.CompleteWebhookDelivery(ctx, delivery, responseStatus, responseBody, sendErr)
+		if err != nil {
+			return err
+		}
+		run.AddCount(string(status), 1)
+	}
+	return ctx.Err()
+}
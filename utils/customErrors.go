@@ -5,3 +5,33 @@ import "errors"
 var UserIsNotMentor = errors.New("user is not mentor")
 var UserImageNotFound = errors.New("user image not found")
 var NotASlice = errors.New("data is not a slice")
+var SessionTransitionForbidden = errors.New("session status change is not allowed for this participant")
+var SessionTransitionNotAllowed = errors.New("session status change is not allowed from the current status")
+var SessionStatusChanged = errors.New("session status was changed by another request")
+var SessionSlotIsTaken = errors.New("session time overlaps with another session of the mentor")
+var MentorBookingInProgress = errors.New("another booking for the mentor is in progress, try again")
+var InvalidTimeZone = errors.New("invalid time zone")
+var SessionTypeNotFound = errors.New("session type not found")
+var InvalidSessionType = errors.New("session type duration or buffers are out of range")
+var InvalidAvailabilityException = errors.New("availability exception type or time range is invalid")
+var InvalidBookingRules = errors.New("booking rules are out of range")
+var SessionTimeInPast = errors.New("session time must be in the future")
+var BookingNoticeTooShort = errors.New("session starts earlier than the mentor's minimum notice allows")
+var BookingTooFarInAdvance = errors.New("session starts later than the mentor accepts bookings")
+var MentorDailyLimitReached = errors.New("mentor has reached the maximum number of sessions for this day")
+var MentorWeeklyLimitReached = errors.New("mentor has reached the maximum number of sessions for this week")
+var MenteePendingLimitReached = errors.New("too many pending requests to this mentor")
+var CancellationReasonTooLong = errors.New("cancellation reason is too long")
+var JobNotFound = errors.New("job not found")
+var InvalidSignedToken = errors.New("link is invalid or was changed")
+var InvalidNotificationPreferences = errors.New("unknown notification category or channel")
+var UserAlreadyExists = errors.New("an account with this email already exists")
+var EmailAlreadyVerified = errors.New("email is already verified")
+var VerificationEmailThrottled = errors.New("verification email was sent recently, try again later")
+var TwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+var TwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+var InvalidTwoFactorCode = errors.New("invalid two-factor code")
+var InvalidWebhookSubscription = errors.New("webhook url must be an absolute http(s) url and events must be known")
+var TwoFactorLocked = errors.New("too many invalid two-factor codes, try again later")
+var EmailClaimLost = errors.New("email claim expired before the delivery result was stored")
+var JobAlreadyRunning = errors.New("job is already running")
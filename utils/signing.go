@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"os"
+	"strings"
+)
+
+// getSigningKey returns the secret used for links which work without logging in.
+func getSigningKey() []byte {
+	return []byte(os.Getenv("LINK_SIGNING_SECRET"))
+}
+
+// SignToken encodes the payload together with its HMAC-SHA256, so it can be put into a URL
+// and checked with VerifySignedToken later.
+func SignToken(payload string) string {
+	key := getSigningKey()
+	if len(key) == 0 {
+		log.Println("SignToken: LINK_SIGNING_SECRET is not set, signed links will be rejected")
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(computeSignature(key, encodedPayload))
+}
+
+// VerifySignedToken returns the payload of a token created by SignToken.
+func VerifySignedToken(token string) (string, error) {
+	key := getSigningKey()
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if len(key) == 0 || !found {
+		return "", InvalidSignedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, computeSignature(key, encodedPayload)) {
+		return "", InvalidSignedToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", InvalidSignedToken
+	}
+	return string(payload), nil
+}
+
+// HashToken returns the hex SHA-256 of a random token, so tokens can be looked up without
+// storing them.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func computeSignature(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
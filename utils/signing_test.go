@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifySignedToken(t *testing.T) {
+	t.Setenv("LINK_SIGNING_SECRET", "test-secret")
+	token := SignToken("user:42")
+	payload, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", token: token, want: "user:42"},
+		{name: "empty payload", token: SignToken(""), want: ""},
+		{name: "no separator", token: payload + signature, wantErr: InvalidSignedToken},
+		{name: "changed payload", token: SignToken("user:43")[:len(payload)] + "." + signature, wantErr: InvalidSignedToken},
+		{name: "changed signature", token: payload + "." + strings.Repeat("A", len(signature)), wantErr: InvalidSignedToken},
+		{name: "invalid signature encoding", token: payload + ".!!!", wantErr: InvalidSignedToken},
+		{name: "empty", token: "", wantErr: InvalidSignedToken},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := VerifySignedToken(test.token)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("VerifySignedToken() error = %v, want %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("VerifySignedToken() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignedTokenWithOtherKey(t *testing.T) {
+	t.Setenv("LINK_SIGNING_SECRET", "test-secret")
+	token := SignToken("user:42")
+	t.Setenv("LINK_SIGNING_SECRET", "other-secret")
+	if _, err := VerifySignedToken(token); !errors.Is(err, InvalidSignedToken) {
+		t.Errorf("VerifySignedToken() error = %v, want %v", err, InvalidSignedToken)
+	}
+	t.Setenv("LINK_SIGNING_SECRET", "")
+	if _, err := VerifySignedToken(token); !errors.Is(err, InvalidSignedToken) {
+		t.Errorf("VerifySignedToken() without key error = %v, want %v", err, InvalidSignedToken)
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token: "", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{token: "abc", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, test := range tests {
+		if got := HashToken(test.token); got != test.want {
+			t.Errorf("HashToken(%q) = %s, want %s", test.token, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors, base32 encoded.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTP(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, test := range tests {
+		got, err := GenerateTOTP(rfc6238Secret, time.Unix(test.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateTOTP(%d) error = %v", test.unix, err)
+		}
+		if got != test.want {
+			t.Errorf("GenerateTOTP(%d) = %s, want %s", test.unix, got, test.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / 30
+	codeAt := func(offset time.Duration) string {
+		code, err := GenerateTOTP(rfc6238Secret, now.Add(offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "current period", secret: rfc6238Secret, code: codeAt(0), wantStep: step, wantOk: true},
+		{name: "previous period", secret: rfc6238Secret, code: codeAt(-30 * time.Second), wantStep: step - 1, wantOk: true},
+		{name: "next period", secret: rfc6238Secret, code: codeAt(30 * time.Second), wantStep: step + 1, wantOk: true},
+		{name: "two periods ago", secret: rfc6238Secret, code: codeAt(-60 * time.Second)},
+		{name: "two periods ahead", secret: rfc6238Secret, code: codeAt(60 * time.Second)},
+		{name: "lower case secret", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: codeAt(0), wantStep: step, wantOk: true},
+		{name: "wrong code", secret: rfc6238Secret, code: "000000"},
+		{name: "short code", secret: rfc6238Secret, code: codeAt(0)[:5]},
+		{name: "invalid secret", secret: "not base32!", code: codeAt(0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotStep, gotOk := ValidateTOTP(test.secret, test.code, now)
+			if gotOk != test.wantOk || gotStep != test.wantStep {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", gotStep, gotOk, test.wantStep, test.wantOk)
+			}
+		})
+	}
+}
+
+// A code stays valid within the skew, so replays are rejected by comparing the returned step
+// with the last used one.
+func TestValidateTOTPReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := GenerateTOTP(rfc6238Secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastUsedStep := int64(0)
+	tests := []struct {
+		name   string
+		at     time.Time
+		wantOk bool
+	}{
+		{name: "first use", at: now, wantOk: true},
+		{name: "same period", at: now, wantOk: false},
+		{name: "next period", at: now.Add(30 * time.Second), wantOk: false},
+	}
+	for _, test := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, code, test.at)
+		accepted := ok && step > lastUsedStep
+		if accepted {
+			lastUsedStep = step
+		}
+		if accepted != test.wantOk {
+			t.Errorf("%s: accepted = %v, want %v", test.name, accepted, test.wantOk)
+		}
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := GenerateTOTP(secret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateTOTP() with generated secret error = %v", err)
+	}
+	if _, ok := ValidateTOTP(secret, code, time.Now()); !ok {
+		t.Errorf("ValidateTOTP() rejected the code of a generated secret")
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[A-Z2-7]{4}-[A-Z2-7]{4}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		code, err := GenerateRecoveryCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !format.MatchString(code) {
+			t.Errorf("GenerateRecoveryCode() = %s, want XXXX-XXXX", code)
+		}
+		if seen[code] {
+			t.Errorf("GenerateRecoveryCode() repeated %s", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestRecoveryCodeHash(t *testing.T) {
+	stored := HashToken(NormalizeRecoveryCode("ABCD-EFGH"))
+	tests := []struct {
+		code      string
+		wantMatch bool
+	}{
+		{code: "ABCD-EFGH", wantMatch: true},
+		{code: "abcd-efgh", wantMatch: true},
+		{code: "ABCDEFGH", wantMatch: true},
+		{code: "  abcdefgh ", wantMatch: true},
+		{code: "ABCD-EFGX", wantMatch: false},
+		{code: "", wantMatch: false},
+	}
+	for _, test := range tests {
+		got := HashToken(NormalizeRecoveryCode(test.code)) == stored
+		if got != test.wantMatch {
+			t.Errorf("hash of %q matches = %v, want %v", test.code, got, test.wantMatch)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
 	"reflect"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -48,18 +52,34 @@ func GetDayOfWeek(day string) time.Weekday {
 	}
 }
 
+// NormalizeEmail returns the form in which emails are stored and looked up.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func TimePtr(t time.Time) *time.Time {
 	return &t
 }
 
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
 func GetFunctionName(i any) string {
 	return runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
 }
 
-func convertUTCtoLocal(utcTime *time.Time, offset int) time.Time {
-	loc := time.FixedZone("Local Timezone", offset)
-	localTime := utcTime.In(loc)
-	return localTime
+// GetLocation returns the IANA time zone by name and falls back to UTC for empty or unknown names.
+func GetLocation(timeZone string) *time.Location {
+	if timeZone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		log.Printf("GetLocation: unknown time zone(%s), UTC is used: %v\n", timeZone, err)
+		return time.UTC
+	}
+	return location
 }
 
 func IsSliceAndLength(data interface{}) (bool, int, error) {
@@ -70,3 +90,13 @@ func IsSliceAndLength(data interface{}) (bool, int, error) {
 		return false, 0, NotASlice
 	}
 }
+
+// GenerateRandomToken returns size random bytes encoded for use in URLs.
+func GenerateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Failed to generate random token: %v\n", err)
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "user@example.com", want: "user@example.com"},
+		{email: "User@Example.COM", want: "user@example.com"},
+		{email: "  user@example.com\t", want: "user@example.com"},
+		{email: "\nUSER+Tag@example.com ", want: "user+tag@example.com"},
+		{email: "first.last@example.com", want: "first.last@example.com"},
+		{email: "", want: ""},
+		{email: "   ", want: ""},
+	}
+	for _, test := range tests {
+		if got := NormalizeEmail(test.email); got != test.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", test.email, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"log"
+	"net/http"
+	"oysterProject/database"
+	"oysterProject/model"
+	"strconv"
+	"time"
+)
+
+const (
+	deliveryTimeout = 10 * time.Second
+	emitTimeout     = 30 * time.Second
+
+	EventHeaderName     = "X-Oyster-Event"
+	DeliveryHeaderName  = "X-Oyster-Delivery"
+	TimestampHeaderName = "X-Oyster-Timestamp"
+	SignatureHeaderName = "X-Oyster-Signature"
+)
+
+var httpClient = &http.Client{Timeout: deliveryTimeout}
+
+func EmitSessionCreated(session *model.SessionResponse) {
+	emit(model.WebhookSessionCreated, newWebhookSession(session), "")
+}
+
+func EmitSessionConfirmed(session *model.SessionResponse) {
+	emit(model.WebhookSessionConfirmed, newWebhookSession(session), "")
+}
+
+func EmitSessionRescheduled(session *model.SessionResponse) {
+	emit(model.WebhookSessionRescheduled, newWebhookSession(session), "")
+}
+
+func EmitSessionCancelled(session *model.SessionResponse) {
+	emit(model.WebhookSessionCancelled, newWebhookSession(session), "")
+}
+
+func EmitReviewCreated(review *model.Review) {
+	data := &model.WebhookReview{
+		ReviewId:  review.ReviewId,
+		SessionId: review.SessionId,
+		MentorId:  review.MentorId,
+		MenteeId:  review.MenteeId,
+		Rating:    review.Rating,
+		Date:      review.Date,
+	}
+	emit(model.WebhookReviewCreated, data, "")
+}
+
+// EmitUserApproved is called by the approval job on every run until the approval email is
+// delivered, the dedupe key keeps a single delivery per subscription.
+func EmitUserApproved(user *model.User) {
+	data := &model.WebhookUser{UserId: user.Id, Name: user.Username, Email: user.Email}
+	emit(model.WebhookUserApproved, data, model.WebhookUserApproved+":"+user.Id.Hex())
+}
+
+func newWebhookSession(session *model.SessionResponse) *model.WebhookSession {
+	data := &model.WebhookSession{
+		SessionId:           session.SessionId,
+		SessionTimeStart:    session.SessionTimeStart,
+		SessionTimeEnd:      session.SessionTimeEnd,
+		NewSessionTimeStart: session.NewSessionTimeStart,
+		NewSessionTimeEnd:   session.NewSessionTimeEnd,
+		Status:              session.Status,
+		CanceledBy:          session.CanceledBy,
+		CanceledAt:          session.CanceledAt,
+	}
+	if session.Mentor != nil {
+		data.MentorId = session.Mentor.UserId
+	}
+	if session.Mentee != nil {
+		data.MenteeId = session.Mentee.UserId
+	}
+	return data
+}
+
+// emit queues a delivery of the event for every active subscription listening to it.
+func emit(event string, data any, dedupeKey string) {
+	ctx, cancel := context.WithTimeout(context.Background(), emitTimeout)
+	defer cancel()
+	subscriptions, err := database.GetWebhookSubscriptionsForEvent(ctx, event)
+	if err != nil || len(subscriptions) == 0 {
+		return
+	}
+	payload := &model.WebhookPayload{
+		Id:        newEventId(dedupeKey),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal %s webhook payload: %v\n", event, err)
+		return
+	}
+	var deliveries []*model.WebhookDelivery
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			SubscriptionId: subscription.Id,
+			EventId:        payload.Id,
+			Event:          event,
+			Payload:        string(body),
+			DedupeKey:      dedupeKey,
+		})
+	}
+	if err = database.InsertWebhookDeliveries(ctx, deliveries); err != nil {
+		log.Printf("Failed to queue %s webhook deliveries: %v\n", event, err)
+	}
+}
+
+// Deliver posts the payload to the subscription. Any response other than 2xx is an error, the
+// status and the body are returned for the delivery log.
+func Deliver(ctx context.Context, delivery *model.WebhookDelivery, subscription *model.WebhookSubscription) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Oyster-Webhooks/1.0")
+	request.Header.Set(EventHeaderName, delivery.Event)
+	request.Header.Set(DeliveryHeaderName, delivery.Id.Hex())
+	request.Header.Set(TimestampHeaderName, timestamp)
+	request.Header.Set(SignatureHeaderName, "sha256="+Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, string(responseBody), fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, string(responseBody), nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.payload". Receivers recompute it with the
+// subscription secret and reject old timestamps to prevent replays.
+func Sign(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newEventId(dedupeKey string) string {
+	if dedupeKey != "" {
+		return dedupeKey
+	}
+	return primitive.NewObjectID().Hex()
+}